@@ -0,0 +1,105 @@
+package golang
+
+import (
+	"github.com/gqlc/graphql/ast"
+	goast "go/ast"
+)
+
+// findImplementers returns the object types which implement each interface or
+// are a member of each union, in the order in which they're declared.
+func findImplementers(types []*ast.TypeDecl) map[string][]string {
+	impls := make(map[string][]string)
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+
+		switch v := ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Object:
+			for _, inter := range v.Object.Interfaces {
+				impls[inter.Name] = append(impls[inter.Name], ts.TypeSpec.Name.Name)
+			}
+		case *ast.TypeSpec_Union:
+			for _, mem := range v.Union.Members {
+				impls[ts.TypeSpec.Name.Name] = append(impls[ts.TypeSpec.Name.Name], mem.Name)
+			}
+		}
+	}
+	return impls
+}
+
+// libraryAbstracts returns the interfaces and unions of type libraries which
+// are implemented by objects of this package.
+func (g *Generator) libraryAbstracts(types []*ast.TypeDecl) (abstracts []string) {
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+
+		name := typeSpecName(ts.TypeSpec)
+		if !g.isLibraryType(name) {
+			continue
+		}
+		for _, obj := range g.implementers[name] {
+			if !g.isLibraryType(obj) {
+				abstracts = append(abstracts, name)
+				break
+			}
+		}
+	}
+	return
+}
+
+// printResolveTypes prints an init func which sets the ResolveType func of the
+// given interfaces and unions. They can't be set in the types' var declarations,
+// since the implementing objects reference their interfaces. The ResolveType funcs
+// of type libraries are extended with the objects of this package instead.
+func (g *Generator) printResolveTypes(abstracts []string) error {
+	b := newBuilder()
+
+	var body []goast.Stmt
+	for _, name := range abstracts {
+		impls, fallback := g.implementers[name], b.name("nil")
+		if g.isLibraryType(name) {
+			impls, fallback = nil, b.call("resolve"+name, b.name("p"))
+			for _, obj := range g.implementers[name] {
+				if !g.isLibraryType(obj) {
+					impls = append(impls, obj)
+				}
+			}
+			body = append(body, b.define("resolve"+name, b.name(g.typeVar(name)+".ResolveType")))
+		}
+
+		var resolve []goast.Stmt
+
+		// Models are resolved by their Go type
+		if g.models {
+			var cases []goast.Stmt
+			for _, obj := range impls {
+				cases = append(cases, b.caseClause([]goast.Expr{b.star(b.name(g.qualify(obj, obj)))}, b.ret(b.name(g.typeVar(obj)))))
+			}
+			resolve = append(resolve, b.typeSwitch("", b.name("p.Value"), cases...))
+		}
+
+		// Anything else must specify its type by name
+		var cases []goast.Stmt
+		for _, obj := range impls {
+			cases = append(cases, b.caseClause([]goast.Expr{b.str(obj)}, b.ret(b.name(g.typeVar(obj)))))
+		}
+		resolve = append(resolve,
+			b.ifStmt(
+				b.define("v, ok", b.assert(b.name("p.Value"), anyMap())),
+				b.name("ok"),
+				b.switchStmt(b.index(b.name("v"), b.str("__typename")), cases...),
+			),
+			b.ret(fallback),
+		)
+
+		typ := b.funcType([]*goast.Field{b.param("p", b.name("graphql.ResolveTypeParams"))}, b.star(b.name("graphql.Object")))
+		body = append(body, b.assign(b.name(g.typeVar(name)+".ResolveType"), b.funcLit(typ, resolve...)))
+	}
+
+	return g.printDecl(b, b.funcDecl("init", body...))
+}
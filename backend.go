@@ -0,0 +1,354 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/gqlc/graphql/ast"
+	goast "go/ast"
+	gotoken "go/token"
+	"sync"
+)
+
+// Backend emits the Go code for the types of a document e.g. for an in-house GraphQL runtime.
+// Backends write to the Generator with P, In and Out, add the packages they use with Import
+// and read the generator options with Options. Every type is emitted into the file the layout
+// option puts it in, while Header, Schema and Footer are emitted into the documents' file.
+//
+// The hooks are called in order: Header, Schema if the document has a schema, which may be
+// implicit, the method of the kind of every type, other than builtin and compiler types,
+// and Footer. Models and resolvers are generated afterwards, as per the options, except for
+// the builtin backends which execute queries themselves, since they decide which they need.
+// Backends which only need some of the hooks can embed BaseBackend.
+type Backend interface {
+	// Header is called before anything is emitted.
+	Header(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error
+
+	// Schema emits the schema.
+	Schema(g *Generator, schema *ast.TypeDecl) error
+
+	Scalar(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error
+	Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error
+	Interface(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error
+	Union(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error
+	Enum(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error
+	Input(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error
+	Directive(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error
+
+	// Footer is called after all types were emitted.
+	Footer(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]func() Backend)
+)
+
+// RegisterBackend registers a backend, which is selected by setting the backend option to its
+// name. A new backend is created for every document, so backends may keep track of what they
+// emitted. It panics if the name is already taken, like database/sql.Register.
+func RegisterBackend(name string, newBackend func() Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if newBackend == nil {
+		panic("golang: RegisterBackend backend is nil")
+	}
+	switch name {
+	case backendGraphQLGo, backendGraphGophers, backendGqlgen, backendExecutor:
+		panic("golang: RegisterBackend called for builtin backend " + name)
+	}
+	if _, dup := backends[name]; dup {
+		panic("golang: RegisterBackend called twice for backend " + name)
+	}
+	backends[name] = newBackend
+}
+
+// lookupBackend returns a new backend with the given name.
+func lookupBackend(name string) (Backend, error) {
+	switch name {
+	case backendGraphQLGo:
+		return new(graphqlGoBackend), nil
+	case backendGraphGophers:
+		return gophersBackend{}, nil
+	case backendGqlgen:
+		return new(gqlgenBackend), nil
+	case backendExecutor:
+		return new(executorBackend), nil
+	}
+
+	backendsMu.RLock()
+	newBackend, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backend: %s", name)
+	}
+	return newBackend(), nil
+}
+
+// emitTypes emits the document through a backend.
+func (g *Generator) emitTypes(b Backend, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	if err := b.Header(g, schema, types); err != nil {
+		return err
+	}
+	if schema != nil {
+		if err := b.Schema(g, schema); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+		if _, ok = ts.TypeSpec.Type.(*ast.TypeSpec_Schema); ok {
+			continue
+		}
+		if isCompilerType(d, ts.TypeSpec) || g.isLibraryType(ts.TypeSpec.Name.Name) {
+			continue
+		}
+
+		g.section(g.typeFile(ts.TypeSpec))
+
+		var err error
+		switch ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Scalar:
+			err = b.Scalar(g, d, ts.TypeSpec)
+		case *ast.TypeSpec_Object:
+			err = b.Object(g, d, ts.TypeSpec)
+		case *ast.TypeSpec_Interface:
+			err = b.Interface(g, d, ts.TypeSpec)
+		case *ast.TypeSpec_Union:
+			err = b.Union(g, d, ts.TypeSpec)
+		case *ast.TypeSpec_Enum:
+			err = b.Enum(g, d, ts.TypeSpec)
+		case *ast.TypeSpec_Input:
+			err = b.Input(g, d, ts.TypeSpec)
+		case *ast.TypeSpec_Directive:
+			err = b.Directive(g, d, ts.TypeSpec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	g.section(g.fileName)
+	return b.Footer(g, schema, types)
+}
+
+// Import adds a package to the imports of the file being generated.
+func (g *Generator) Import(path string) {
+	g.addImport(path)
+}
+
+// Options returns the options of the document being generated.
+func (g *Generator) Options() *Options {
+	return g.opts
+}
+
+// BaseBackend emits nothing. Backends embedding it only implement the hooks they need.
+type BaseBackend struct{}
+
+func (BaseBackend) Header(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	return nil
+}
+
+func (BaseBackend) Schema(g *Generator, schema *ast.TypeDecl) error { return nil }
+
+func (BaseBackend) Scalar(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error    { return nil }
+func (BaseBackend) Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error    { return nil }
+func (BaseBackend) Interface(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error { return nil }
+func (BaseBackend) Union(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error     { return nil }
+func (BaseBackend) Enum(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error      { return nil }
+func (BaseBackend) Input(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error     { return nil }
+func (BaseBackend) Directive(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error { return nil }
+
+func (BaseBackend) Footer(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	return nil
+}
+
+// graphqlGoBackend emits a var for every type, which is constructed with graphql-go,
+// along with the schema, which is constructed in init.
+type graphqlGoBackend struct {
+	// reachable contains the types reachable from the schema, if only those are included
+	reachable map[string]bool
+
+	directives, abstracts, named []string
+	emitted                      int
+}
+
+func (b *graphqlGoBackend) Header(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	if g.opts.ReachableTypes && schema != nil {
+		b.reachable = g.findReachable(types)
+	}
+
+	// Find any reference cycles between types
+	g.cycles = findCycles(types)
+	return nil
+}
+
+func (b *graphqlGoBackend) Schema(g *Generator, schema *ast.TypeDecl) error {
+	if err := g.printSchemaVar(); err != nil {
+		return err
+	}
+	g.P()
+	return nil
+}
+
+// declare keeps track of a type, which is about to be declared.
+func (b *graphqlGoBackend) declare(g *Generator, ts *ast.TypeSpec) {
+	if b.emitted > 0 {
+		g.P()
+	}
+	b.emitted++
+
+	name := ts.Name.Name
+	switch ts.Type.(type) {
+	case *ast.TypeSpec_Directive:
+		b.directives = append(b.directives, name)
+	case *ast.TypeSpec_Interface, *ast.TypeSpec_Union:
+		if len(g.implementers[name]) > 0 {
+			b.abstracts = append(b.abstracts, name)
+		}
+	}
+	if _, ok := ts.Type.(*ast.TypeSpec_Directive); !ok && (b.reachable == nil || b.reachable[name]) {
+		b.named = append(b.named, name)
+	}
+}
+
+func (b *graphqlGoBackend) Scalar(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b.declare(g, ts)
+	return g.generateScalar(ts.Name.Name, g.opts.Descriptions, d.Doc, ts)
+}
+
+func (b *graphqlGoBackend) Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b.declare(g, ts)
+	return g.generateObject(ts.Name.Name, g.opts.Descriptions, d.Doc, ts)
+}
+
+func (b *graphqlGoBackend) Interface(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b.declare(g, ts)
+	return g.generateInterface(ts.Name.Name, g.opts.Descriptions, d.Doc, ts)
+}
+
+func (b *graphqlGoBackend) Union(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b.declare(g, ts)
+	return g.generateUnion(ts.Name.Name, g.opts.Descriptions, d.Doc, ts)
+}
+
+func (b *graphqlGoBackend) Enum(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b.declare(g, ts)
+	return g.generateEnum(ts.Name.Name, g.opts.Descriptions, d.Doc, ts)
+}
+
+func (b *graphqlGoBackend) Input(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b.declare(g, ts)
+	return g.generateInput(ts.Name.Name, g.opts.Descriptions, d.Doc, ts)
+}
+
+func (b *graphqlGoBackend) Directive(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b.declare(g, ts)
+	return g.generateDirective(ts.Name.Name, g.opts.Descriptions, d.Doc, ts)
+}
+
+// Footer prints the fields closing reference cycles, the ResolveType funcs and the
+// construction of the schema, or the registration of the types of a type library.
+func (b *graphqlGoBackend) Footer(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	// Generate deferred fields
+	if len(g.deferred) > 0 {
+		g.P()
+		if err := g.printDeferred(); err != nil {
+			return err
+		}
+	}
+
+	// Generate ResolveType funcs
+	abstracts := append(b.abstracts, g.libraryAbstracts(types)...)
+	if len(abstracts) > 0 {
+		g.P()
+		if err := g.printResolveTypes(abstracts); err != nil {
+			return err
+		}
+	}
+
+	if g.jsonLiteral {
+		g.P()
+		if err := g.printJSONLiteral(); err != nil {
+			return err
+		}
+	}
+
+	if schema == nil {
+		g.P()
+		return g.printRegisterTypes(b.named)
+	}
+
+	g.P()
+	return g.printSchemaInit(schema, b.named, b.directives)
+}
+
+// printSchemaVar prints the declaration of the schema, which is constructed by the init func.
+func (g *Generator) printSchemaVar() error {
+	b := newBuilder()
+	return g.printDecl(b, b.zeroVar("Schema", b.name("graphql.Schema")))
+}
+
+// printSchemaInit prints the init func which constructs the schema.
+func (g *Generator) printSchemaInit(schema *ast.TypeDecl, named, directives []string) error {
+	b := newBuilder()
+
+	var body []goast.Stmt
+	if len(g.libraries) > 0 {
+		body = append(body, g.libraryTypes(b, named)...)
+	}
+	body = append(body, b.varStmt("err", b.name("error")))
+
+	cfg := b.block(b.name("graphql.SchemaConfig"))
+	rootOps := schema.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Schema).Schema.RootOps.List
+	for _, op := range rootOps {
+		var field string
+		switch op.Name.Name {
+		case "query":
+			field = "Query"
+		case "mutation":
+			field = "Mutation"
+		case "subscription":
+			field = "Subscription"
+		}
+		cfg.Elts = append(cfg.Elts, b.field(field, b.name(g.typeVar(op.Type.(*ast.Field_Ident).Ident.Name))))
+	}
+
+	// Include all types, since some are only reachable through interfaces, if at all
+	switch {
+	case len(g.libraries) > 0:
+		cfg.Elts = append(cfg.Elts, b.field("Types", b.name("types")))
+	case len(named) > 0:
+		types := b.block(&goast.ArrayType{Elt: b.name("graphql.Type")})
+		for _, name := range named {
+			types.Elts = append(types.Elts, b.name(name+string(typeSuffix)))
+		}
+		cfg.Elts = append(cfg.Elts, b.field("Types", types))
+	}
+
+	// Include all declared directives along with the builtin ones
+	if len(directives) > 0 {
+		dirs := b.block(&goast.ArrayType{Elt: &goast.StarExpr{X: b.name("graphql.Directive")}},
+			b.name("graphql.IncludeDirective"),
+			b.name("graphql.SkipDirective"),
+			b.name("graphql.DeprecatedDirective"),
+		)
+		for _, name := range directives {
+			dirs.Elts = append(dirs.Elts, b.name(name+string(directiveSuffix)))
+		}
+		cfg.Elts = append(cfg.Elts, b.field("Directives", dirs))
+	}
+
+	body = append(body,
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{b.name("Schema"), b.name("err")},
+			Tok: gotoken.ASSIGN,
+			Rhs: []goast.Expr{b.call("graphql.NewSchema", cfg)},
+		},
+		b.ifStmt(nil, b.binary(b.name("err"), gotoken.NEQ, b.name("nil")), b.exprStmt(b.call("panic", b.name("err")))),
+	)
+	return g.printDecl(b, b.funcDecl("init", body...))
+}
@@ -0,0 +1,178 @@
+package golang
+
+import "github.com/gqlc/graphql/ast"
+
+// findCycles finds all the reference cycles between the given types.
+// The returned map contains every type which is part of a cycle
+// mapped to the cycle it belongs to.
+//
+// Cycles are found by computing the strongly connected components
+// of the type reference graph, via Tarjan's algorithm.
+func findCycles(types []*ast.TypeDecl) map[string]int {
+	refs := make(map[string][]string, len(types))
+	names := make([]string, 0, len(types))
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+
+		name, typeRefs := ts.TypeSpec.Name, typeRefs(ts.TypeSpec)
+		if name == nil || typeRefs == nil {
+			continue
+		}
+
+		names = append(names, name.Name)
+		refs[name.Name] = typeRefs
+	}
+
+	t := &tarjan{
+		refs:   refs,
+		index:  make(map[string]int, len(refs)),
+		low:    make(map[string]int, len(refs)),
+		on:     make(map[string]bool, len(refs)),
+		cycles: make(map[string]int),
+	}
+	for _, name := range names {
+		if _, visited := t.index[name]; !visited {
+			t.connect(name)
+		}
+	}
+	return t.cycles
+}
+
+// typeRefs returns the names of all the types directly referenced by a type.
+func typeRefs(ts *ast.TypeSpec) (refs []string) {
+	switch v := ts.Type.(type) {
+	case *ast.TypeSpec_Object:
+		for _, inter := range v.Object.Interfaces {
+			refs = append(refs, inter.Name)
+		}
+		refs = appendFieldRefs(refs, v.Object.Fields)
+	case *ast.TypeSpec_Interface:
+		refs = appendFieldRefs(refs, v.Interface.Fields)
+	case *ast.TypeSpec_Union:
+		for _, mem := range v.Union.Members {
+			refs = append(refs, mem.Name)
+		}
+	case *ast.TypeSpec_Input:
+		refs = appendInputRefs(refs, v.Input.Fields)
+	default:
+		return nil
+	}
+	if refs == nil {
+		refs = []string{}
+	}
+	return
+}
+
+func appendFieldRefs(refs []string, fields *ast.FieldList) []string {
+	if fields == nil {
+		return refs
+	}
+
+	for _, f := range fields.List {
+		if id := unwrapType(fieldType(f)); id != nil {
+			refs = append(refs, id.Name)
+		}
+		refs = appendInputRefs(refs, f.Args)
+	}
+	return refs
+}
+
+func appendInputRefs(refs []string, args *ast.InputValueList) []string {
+	if args == nil {
+		return refs
+	}
+
+	for _, a := range args.List {
+		if id := unwrapType(inputType(a)); id != nil {
+			refs = append(refs, id.Name)
+		}
+	}
+	return refs
+}
+
+// tarjan holds the state of Tarjan's strongly connected components algorithm.
+type tarjan struct {
+	refs map[string][]string
+
+	n     int
+	index map[string]int
+	low   map[string]int
+	stack []string
+	on    map[string]bool
+
+	ncycles int
+	cycles  map[string]int
+}
+
+func (t *tarjan) connect(name string) {
+	t.index[name] = t.n
+	t.low[name] = t.n
+	t.n++
+	t.stack = append(t.stack, name)
+	t.on[name] = true
+
+	self := false
+	for _, ref := range t.refs[name] {
+		if ref == name {
+			self = true
+		}
+		if _, exists := t.refs[ref]; !exists {
+			continue
+		}
+
+		if _, visited := t.index[ref]; !visited {
+			t.connect(ref)
+			if t.low[ref] < t.low[name] {
+				t.low[name] = t.low[ref]
+			}
+			continue
+		}
+
+		if t.on[ref] && t.index[ref] < t.low[name] {
+			t.low[name] = t.index[ref]
+		}
+	}
+
+	if t.low[name] != t.index[name] {
+		return
+	}
+
+	// Pop the component off of the stack
+	i := len(t.stack) - 1
+	for t.stack[i] != name {
+		i--
+	}
+	comp := t.stack[i:]
+	t.stack = t.stack[:i]
+	for _, c := range comp {
+		t.on[c] = false
+	}
+
+	// Single types only form a cycle when they reference themselves
+	if len(comp) == 1 && !self {
+		return
+	}
+
+	for _, c := range comp {
+		t.cycles[c] = t.ncycles
+	}
+	t.ncycles++
+}
+
+// isCyclic reports whether a reference from typ to ref closes a reference cycle.
+func (g *Generator) isCyclic(typ string, ref *ast.Ident) bool {
+	if g.cycles == nil || ref == nil {
+		return false
+	}
+
+	c, ok := g.cycles[typ]
+	if !ok {
+		return false
+	}
+
+	rc, ok := g.cycles[ref.Name]
+	return ok && c == rc
+}
@@ -0,0 +1,296 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/gqlc/graphql/ast"
+	"github.com/gqlc/graphql/token"
+	goast "go/ast"
+	gotoken "go/token"
+)
+
+// generateDecoders generates the funcs which decode field arguments
+// and input objects, as given by graphql-go, into their Go models.
+func (g *Generator) generateDecoders(types []*ast.TypeDecl) error {
+	b := newBuilder()
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok || isCompilerType(d, ts.TypeSpec) {
+			continue
+		}
+
+		var decls []goast.Decl
+		switch v := ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Object:
+			name := ts.TypeSpec.Name.Name
+			if g.isLibraryType(name) {
+				continue
+			}
+
+			g.section(g.typeFile(ts.TypeSpec))
+			for _, f := range v.Object.Fields.List {
+				if f.Args.NumValues() == 0 {
+					continue
+				}
+				g.addImport("fmt")
+
+				args := argsName(name, f)
+				typ := b.funcType([]*goast.Field{b.param("args", anyMap())})
+				typ.Results = decodeResults(b, b.name(args))
+
+				var body []goast.Stmt
+				for _, a := range f.Args.List {
+					body = append(body, g.decodeField(b, "args", a, fmt.Sprintf("%s.%s(%s)", name, f.Name.Name, a.Name.Name)))
+				}
+				body = append(body, b.ret())
+				decls = append(decls, b.docFunc("decode"+args+" decodes the arguments of "+name+"."+f.Name.Name+".", "decode"+args, typ, body...))
+			}
+		case *ast.TypeSpec_Input:
+			// Inputs of type libraries are decoded here too, since their decoders aren't exported
+			name := ts.TypeSpec.Name.Name
+			model := g.qualify(name, name)
+			g.section(g.typeFile(ts.TypeSpec))
+			g.addImport("fmt")
+
+			typ := b.funcType([]*goast.Field{b.param("val", anyType())})
+			typ.Results = decodeResults(b, b.star(b.name(model)))
+
+			body := []goast.Stmt{
+				b.define("m, ok", b.assert(b.name("val"), anyMap())),
+				b.ifStmt(nil, b.not(b.name("ok")),
+					b.ret(b.name("nil"), b.call("fmt.Errorf", b.str("expected "+name+" but got %T"), b.name("val"))),
+				),
+				b.assign(b.name("res"), b.call("new", b.name(model))),
+			}
+			for _, f := range v.Input.Fields.List {
+				body = append(body, g.decodeField(b, "m", f, name+"."+f.Name.Name))
+			}
+			body = append(body, b.ret())
+			decls = append(decls, b.docFunc("decode"+name+" decodes a "+name+" input object.", "decode"+name, typ, body...))
+		}
+
+		if err := g.printDecls(b, decls...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeResults returns the results of a decode func, i.e. the decoded value and an error.
+func decodeResults(b *builder, typ goast.Expr) *goast.FieldList {
+	return &goast.FieldList{List: []*goast.Field{b.param("res", typ), b.param("err", b.name("error"))}}
+}
+
+// decodeField returns the decoding of a single argument or input field, from the given
+// map, into res. Missing values are replaced by their default value, while explicit
+// nulls are kept, as per the spec.
+func (g *Generator) decodeField(b *builder, m string, a *ast.InputValue, path string) goast.Stmt {
+	// A null default is the same as no default at all
+	var def interface{}
+	switch v := a.Default.(type) {
+	case *ast.InputValue_BasicLit:
+		def = v.BasicLit
+		if v.BasicLit.Kind == token.Token_NULL {
+			def = nil
+		}
+	case *ast.InputValue_CompositeLit:
+		def = v.CompositeLit
+		if b, ok := v.CompositeLit.Value.(*ast.CompositeLit_BasicLit); ok && b.BasicLit.Kind == token.Token_NULL {
+			def = nil
+		}
+	}
+
+	typ := inputType(a)
+	_, nonNull := typ.(*ast.NonNull)
+	dst := func() goast.Expr { return b.name("res." + goName(a.Name.Name)) }
+
+	val := b.index(b.name(m), b.str(a.Name.Name))
+	init := b.define("v", val)
+	if def != nil {
+		init = b.define("v, ok", val)
+	}
+	s := b.ifStmt(init, b.binary(b.name("v"), gotoken.NEQ, b.name("nil")), g.decodeValue(b, dst, "v", typ, path, nil, 0)...)
+
+	var null goast.Stmt
+	if nonNull {
+		null = b.ret(b.name("res"), b.call("fmt.Errorf", b.str(path+": must not be null")))
+	}
+	if def != nil {
+		body := append([]goast.Stmt{b.typedVar("v", anyType(), g.valExpr(b, def, typ))}, g.decodeValue(b, dst, "v", typ, path, nil, 0)...)
+		missing := b.ifStmt(nil, b.not(b.name("ok")), body...)
+		if null != nil {
+			b.orElse(missing, null)
+		}
+		return b.orElse(s, missing)
+	}
+	if null != nil {
+		return b.orElse(s, null)
+	}
+	return s
+}
+
+// decodeValue returns the decoding of the, non-nil, value src into dst.
+// Any errors are reported along with the path to the value; for list elements
+// the path is a format string which is given the indexes in pathArgs.
+func (g *Generator) decodeValue(b *builder, dst func() goast.Expr, src string, typ interface{}, path string, pathArgs []string, depth int) []goast.Stmt {
+	nonNull := false
+	if v, ok := typ.(*ast.NonNull); ok {
+		nonNull = true
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			typ = w.Ident
+		case *ast.NonNull_List:
+			typ = w.List
+		}
+	}
+
+	// fail returns the error of the value, whose format is given the path args and arg
+	fail := func(format, arg string) goast.Stmt {
+		args := []goast.Expr{b.str(path + format)}
+		for _, p := range pathArgs {
+			args = append(args, b.name(p))
+		}
+		args = append(args, b.name(arg))
+		return b.ret(b.name("res"), b.call("fmt.Errorf", args...))
+	}
+
+	x := fmt.Sprintf("x%d", depth)
+	switch v := typ.(type) {
+	case *ast.Ident:
+		var gqlType, goType string
+		switch v.Name {
+		case "Int":
+			gqlType, goType = "Int", "int"
+		case "Float":
+			return []goast.Stmt{
+				b.varStmt(x, b.name("float64")),
+				b.typeSwitch("n", b.name(src),
+					b.caseClause([]goast.Expr{b.name("float64")}, b.assign(b.name(x), b.name("n"))),
+					b.caseClause([]goast.Expr{b.name("int")}, b.assign(b.name(x), b.call("float64", b.name("n")))),
+					b.caseClause(nil, fail(": expected Float but got %T", src)),
+				),
+				decodeAssign(b, dst, x, nonNull),
+			}
+		case "ID":
+			// IDs may also be given as Int literals, which are serialized as strings
+			g.addImport("strconv")
+			return []goast.Stmt{
+				b.varStmt(x, b.name("string")),
+				b.typeSwitch("id", b.name(src),
+					b.caseClause([]goast.Expr{b.name("string")}, b.assign(b.name(x), b.name("id"))),
+					b.caseClause([]goast.Expr{b.name("int")}, b.assign(b.name(x), b.call("strconv.Itoa", b.name("id")))),
+					b.caseClause(nil, fail(": expected ID but got %T", src)),
+				),
+				decodeAssign(b, dst, x, nonNull),
+			}
+		case "String":
+			gqlType, goType = "String", "string"
+		case "Boolean":
+			gqlType, goType = "Boolean", "bool"
+		default:
+			ts := g.typeSpecs[v.Name]
+			if ts == nil {
+				gqlType, goType = v.Name, g.goType(v, true)
+				break
+			}
+
+			switch ts.Type.(type) {
+			case *ast.TypeSpec_Scalar:
+				s, ok := g.scalars[v.Name]
+				if !ok || s.kind == "json" {
+					return []goast.Stmt{b.assign(dst(), b.name(src))}
+				}
+
+				// Values which weren't parsed yet, e.g. defaults, are parsed by the scalar
+				parse := b.assert(b.call(g.typeVar(v.Name)+".ParseValue", b.name(src)), b.typ(s.typ))
+				return []goast.Stmt{
+					b.define(x+", ok", b.assert(b.name(src), b.typ(s.typ))),
+					b.ifStmt(nil, b.not(b.name("ok")), b.assignAll([]goast.Expr{b.name(x), b.name("ok")}, parse)),
+					b.ifStmt(nil, b.not(b.name("ok")), fail(": expected "+v.Name+" but got %T", src)),
+					decodeAssign(b, dst, x, nonNull),
+				}
+			case *ast.TypeSpec_Enum:
+				enum := g.qualify(v.Name, v.Name)
+				values := ts.Type.(*ast.TypeSpec_Enum).Enum.Values.List
+
+				// Only the declared values are valid, since the string may be anything
+				consts := make([]goast.Expr, len(values))
+				for i, ev := range values {
+					consts[i] = b.name(g.qualify(v.Name, enumConst(v.Name, ev.Name.Name)))
+				}
+				return []goast.Stmt{
+					b.varStmt(x, b.name(enum)),
+					b.typeSwitch("e", b.name(src),
+						b.caseClause([]goast.Expr{b.name(enum)}, b.assign(b.name(x), b.name("e"))),
+						b.caseClause([]goast.Expr{b.name("string")}, b.assign(b.name(x), b.call(enum, b.name("e")))),
+						b.caseClause(nil, fail(": expected "+v.Name+" but got %T", src)),
+					),
+					b.switchStmt(b.name(x),
+						b.caseClause(consts),
+						b.caseClause(nil, fail(": invalid "+v.Name+" value %q", x)),
+					),
+					decodeAssign(b, dst, x, nonNull),
+				}
+			case *ast.TypeSpec_Input:
+				return []goast.Stmt{
+					b.define(x+", err", b.call("decode"+v.Name, b.name(src))),
+					b.ifErr(b.name("res"), g.pathErr(b, path, pathArgs)),
+					b.assign(dst(), b.name(x)),
+				}
+			default:
+				gqlType, goType = v.Name, g.goType(v, true)
+			}
+		}
+
+		return []goast.Stmt{
+			b.define(x+", ok", b.assert(b.name(src), b.typ(goType))),
+			b.ifStmt(nil, b.not(b.name("ok")), fail(": expected "+gqlType+" but got %T", src)),
+			decodeAssign(b, dst, x, nonNull || goType[0] == '*'),
+		}
+	case *ast.List:
+		var elem interface{}
+		switch w := v.Type.(type) {
+		case *ast.List_Ident:
+			elem = w.Ident
+		case *ast.List_List:
+			elem = w.List
+		case *ast.List_NonNull:
+			elem = w.NonNull
+		}
+
+		l, i, e := fmt.Sprintf("l%d", depth), fmt.Sprintf("i%d", depth), fmt.Sprintf("e%d", depth)
+		null := b.branch(gotoken.CONTINUE)
+		if _, ok := elem.(*ast.NonNull); ok {
+			null = fail("[%d]: must not be null", i)
+		}
+		elemDst := func() goast.Expr { return b.index(b.name(x), b.name(i)) }
+
+		body := append([]goast.Stmt{b.ifStmt(nil, b.binary(b.name(e), gotoken.EQL, b.name("nil")), null)},
+			g.decodeValue(b, elemDst, e, elem, path+"[%d]", append(pathArgs[:len(pathArgs):len(pathArgs)], i), depth+1)...)
+		return []goast.Stmt{
+			b.define(l+", ok", b.assert(b.name(src), anyList())),
+			b.ifStmt(nil, b.not(b.name("ok")), b.assign(b.name(l), b.inline(anyList(), b.name(src)))),
+			b.define(x, b.call("make", b.typ(g.goType(v, true)), b.call("len", b.name(l)))),
+			b.rangeStmt(i, e, b.name(l), body...),
+			b.assign(dst(), b.name(x)),
+		}
+	}
+	return nil
+}
+
+// pathErr returns the error of a decoded input object, along with the path to the value.
+func (g *Generator) pathErr(b *builder, path string, pathArgs []string) goast.Expr {
+	args := []goast.Expr{b.str(path + ": %s")}
+	for _, p := range pathArgs {
+		args = append(args, b.name(p))
+	}
+	return b.call("fmt.Errorf", append(args, b.name("err"))...)
+}
+
+// decodeAssign returns the assignment of a decoded value, taking
+// its address when the destination is nullable.
+func decodeAssign(b *builder, dst func() goast.Expr, x string, nonNull bool) goast.Stmt {
+	if nonNull {
+		return b.assign(dst(), b.name(x))
+	}
+	return b.assign(dst(), b.addr(b.name(x)))
+}
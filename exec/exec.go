@@ -0,0 +1,369 @@
+// Package exec is the runtime of the executors generated by the executor backend. It parses
+// queries, coerces their variables and collects their fields, while the execution itself
+// is generated for every schema i.e. without reflection.
+package exec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Error is an error which occurred while parsing or executing a query.
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Locations[0].Line, e.Locations[0].Column, e.Message)
+}
+
+// Response is the result of a request.
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []*Error    `json:"errors,omitempty"`
+
+	// executed reports whether the request was executed, since the data of
+	// requests which failed before they were executed is left out
+	executed bool
+}
+
+// MarshalJSON marshals the response, leaving out its data if it's nil and the request wasn't executed.
+func (r Response) MarshalJSON() ([]byte, error) {
+	if r.executed || r.Data != nil {
+		type response Response
+		return json.Marshal(response(r))
+	}
+
+	return json.Marshal(struct {
+		Errors []*Error `json:"errors,omitempty"`
+	}{r.Errors})
+}
+
+// ErrorResponse returns the response to a request which failed before it was executed.
+func ErrorResponse(err error) *Response {
+	switch e := err.(type) {
+	case *Error:
+		return &Response{Errors: []*Error{e}}
+	case Errors:
+		return &Response{Errors: e}
+	}
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+// Scalar serializes and parses the values of a custom scalar.
+type Scalar struct {
+	Name        string
+	Description string
+
+	// Serialize returns the value which represents the given Go value in a response, or
+	// nil if it can't be represented. ParseValue returns the Go value of an argument.
+	Serialize  func(value interface{}) interface{}
+	ParseValue func(value interface{}) interface{}
+}
+
+// Request is the execution of an operation, along with its variables and errors.
+type Request struct {
+	Operation *Operation
+	Fragments map[string]*Fragment
+	Vars      map[string]interface{}
+
+	schema *Schema
+
+	errors []*Error
+}
+
+// NewRequest parses a query, validates it against the schema and selects the operation with
+// the given name, which may be empty if there's a single one. Variables are given as decoded
+// by encoding/json, where any integral numbers are turned into ints, and are checked against
+// their definitions.
+func NewRequest(query, operationName string, vars map[string]interface{}, schema *Schema) (*Request, error) {
+	doc, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if err = Validate(schema, doc); err != nil {
+		return nil, err
+	}
+
+	r := &Request{Fragments: doc.Fragments, Vars: make(map[string]interface{}), schema: schema}
+	for _, op := range doc.Operations {
+		if operationName == "" && len(doc.Operations) > 1 {
+			return nil, &Error{Message: "operation name is required for documents with multiple operations"}
+		}
+		if operationName == "" || op.Name == operationName {
+			r.Operation = op
+			break
+		}
+	}
+	if r.Operation == nil {
+		return nil, &Error{Message: fmt.Sprintf("unknown operation %q", operationName)}
+	}
+
+	for _, def := range r.Operation.Vars {
+		v, ok := vars[def.Name]
+		if !ok && def.HasDefault {
+			v, ok = def.Default, true
+		}
+		if v == nil && def.Type.NonNull {
+			return nil, &Error{
+				Message:   fmt.Sprintf("variable $%s of type %s must not be null", def.Name, def.Type),
+				Locations: []Location{def.Loc},
+			}
+		}
+		if ok {
+			r.Vars[def.Name] = normalize(v)
+		}
+	}
+	return r, nil
+}
+
+// normalize turns any integral numbers of a JSON value into ints, which
+// is how Int arguments are given by a query.
+func normalize(v interface{}) interface{} {
+	switch w := v.(type) {
+	case float64:
+		if w == math.Trunc(w) && math.Abs(w) <= math.MaxInt32 {
+			return int(w)
+		}
+	case []interface{}:
+		l := make([]interface{}, len(w))
+		for i, e := range w {
+			l[i] = normalize(e)
+		}
+		return l
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(w))
+		for k, e := range w {
+			m[k] = normalize(e)
+		}
+		return m
+	}
+	return v
+}
+
+// Error records an error of the given field.
+func (r *Request) Error(f *Field, err error) {
+	r.errors = append(r.errors, &Error{Message: err.Error(), Locations: []Location{f.Loc}})
+}
+
+// Errorf records an error of the given field, formatted as by fmt.Sprintf.
+func (r *Request) Errorf(f *Field, format string, args ...interface{}) {
+	r.errors = append(r.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{f.Loc}})
+}
+
+// Response returns the response of the given data, along with any errors recorded
+// so far. The errors are then cleared, so a request can respond more than once.
+func (r *Request) Response(data interface{}) *Response {
+	resp := &Response{Data: data, Errors: r.errors, executed: true}
+	r.errors = nil
+	return resp
+}
+
+// CollectFields returns the fields a selection set selects on an object of the given type. Fields
+// are merged by their response key, fragments are expanded and the @skip and @include directives
+// are applied. The arguments of the returned fields have their variables replaced.
+func (r *Request) CollectFields(typ string, sels []Selection) []*Field {
+	var fields []*Field
+	r.collectFields(typ, sels, make(map[string]*Field), &fields, make(map[string]bool))
+	return fields
+}
+
+func (r *Request) collectFields(typ string, sels []Selection, keys map[string]*Field, fields *[]*Field, visited map[string]bool) {
+	for _, sel := range sels {
+		switch s := sel.(type) {
+		case *Field:
+			if !r.include(s.Directives) {
+				continue
+			}
+			if f, ok := keys[s.Alias]; ok {
+				f.Selections = append(f.Selections[:len(f.Selections):len(f.Selections)], s.Selections...)
+				continue
+			}
+
+			f := &Field{Alias: s.Alias, Name: s.Name, Directives: s.Directives, Selections: s.Selections, Loc: s.Loc}
+			if len(s.Args) > 0 {
+				f.Args = r.value(s.Args).(map[string]interface{})
+			}
+			keys[s.Alias] = f
+			*fields = append(*fields, f)
+		case *FragmentSpread:
+			frag, ok := r.Fragments[s.Name]
+			if !ok || visited[s.Name] || !r.include(s.Directives) || !r.applies(typ, frag.TypeCondition) {
+				continue
+			}
+			visited[s.Name] = true
+			r.collectFields(typ, frag.Selections, keys, fields, visited)
+		case *InlineFragment:
+			if !r.include(s.Directives) || !r.applies(typ, s.TypeCondition) {
+				continue
+			}
+			r.collectFields(typ, s.Selections, keys, fields, visited)
+		}
+	}
+}
+
+// include reports whether a selection is included, as per the @skip and @include directives.
+func (r *Request) include(dirs []*Directive) bool {
+	for _, d := range dirs {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		if b, _ := r.value(d.Args["if"]).(bool); b == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// applies reports whether a fragment with the given type condition applies to an object type.
+func (r *Request) applies(typ, cond string) bool {
+	if cond == "" || cond == typ {
+		return true
+	}
+	if t := r.schema.Type(cond); t != nil {
+		for _, obj := range t.PossibleTypes {
+			if obj == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// value returns a value with its variables replaced. Fields of input objects
+// which refer to undefined variables are left out, as if they weren't given.
+func (r *Request) value(v interface{}) interface{} {
+	switch w := v.(type) {
+	case Variable:
+		return r.Vars[string(w)]
+	case []interface{}:
+		l := make([]interface{}, len(w))
+		for i, e := range w {
+			l[i] = r.value(e)
+		}
+		return l
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(w))
+		for k, e := range w {
+			if name, ok := e.(Variable); ok {
+				if _, ok = r.Vars[string(name)]; !ok {
+					continue
+				}
+			}
+			m[k] = r.value(e)
+		}
+		return m
+	}
+	return v
+}
+
+// Object is the result of a selection set, which keeps its fields in order.
+type Object struct {
+	keys   []string
+	values []interface{}
+}
+
+// NewObject returns an object with room for the given number of fields.
+func NewObject(n int) *Object {
+	return &Object{keys: make([]string, 0, n), values: make([]interface{}, 0, n)}
+}
+
+// Set appends a field to the object.
+func (o *Object) Set(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// Get returns the value of a field.
+func (o *Object) Get(key string) (interface{}, bool) {
+	for i, k := range o.keys {
+		if k == key {
+			return o.values[i], true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := o.writeJSON(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (o *Object) writeJSON(b *bytes.Buffer) error {
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeString(b, k)
+		b.WriteByte(':')
+		if err := writeJSON(b, o.values[i]); err != nil {
+			return err
+		}
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+// writeJSON writes a value as JSON, without reflection for the values executors return.
+func writeJSON(b *bytes.Buffer, v interface{}) error {
+	switch w := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case *Object:
+		if w == nil {
+			b.WriteString("null")
+			return nil
+		}
+		return w.writeJSON(b)
+	case []interface{}:
+		b.WriteByte('[')
+		for i, e := range w {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJSON(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case string:
+		writeString(b, w)
+	case bool:
+		b.WriteString(strconv.FormatBool(w))
+	case int:
+		b.WriteString(strconv.Itoa(w))
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+	}
+	return nil
+}
+
+// writeString writes a JSON string, escaped as by encoding/json.
+func writeString(b *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' || c >= 0x80 {
+			data, _ := json.Marshal(s)
+			b.Write(data)
+			return
+		}
+	}
+	b.WriteByte('"')
+	b.WriteString(s)
+	b.WriteByte('"')
+}
@@ -0,0 +1,371 @@
+package exec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`query Q($id: ID!, $n: [Int!] = [1]) {
+	a: node(id: $id, in: {x: 1.5, y: [true, null, RED]}) @include(if: true) { ...F }
+}
+
+fragment F on User { name(s: """
+	 block
+	""") }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "Q" {
+		t.Fatalf("expected query Q but got %s %s", op.Type, op.Name)
+	}
+	if op.Vars[0].Type.String() != "ID!" || op.Vars[1].Type.String() != "[Int!]" {
+		t.Fatalf("expected variable types ID! and [Int!] but got %s and %s", op.Vars[0].Type, op.Vars[1].Type)
+	}
+	if !reflect.DeepEqual(op.Vars[1].Default, []interface{}{1}) {
+		t.Fatalf("expected default [1] but got %#v", op.Vars[1].Default)
+	}
+
+	f := op.Selections[0].(*Field)
+	if f.Alias != "a" || f.Name != "node" || f.Loc != (Location{Line: 2, Column: 2}) {
+		t.Fatalf("expected a: node at 2:2 but got %s: %s at %v", f.Alias, f.Name, f.Loc)
+	}
+	args := map[string]interface{}{
+		"id": Variable("id"),
+		"in": map[string]interface{}{"x": 1.5, "y": []interface{}{true, nil, "RED"}},
+	}
+	if !reflect.DeepEqual(f.Args, args) {
+		t.Fatalf("expected args %#v but got %#v", args, f.Args)
+	}
+
+	name := doc.Fragments["F"].Selections[0].(*Field)
+	if name.Args["s"] != "block" {
+		t.Fatalf("expected block string but got %q", name.Args["s"])
+	}
+
+	t.Run("SyntaxError", func(subT *testing.T) {
+		_, err := Parse("{ a(b: ) }")
+		if err == nil || err.Error() != `1:8: syntax error: unexpected ")"` {
+			subT.Fatalf("expected syntax error but got %v", err)
+		}
+	})
+}
+
+// testSchema is the schema the requests of tests are validated against.
+var testSchema = NewSchema(Schema{
+	Query: "Query",
+	Types: []*TypeDef{
+		{Kind: KindObject, Name: "Query", Fields: []*FieldDef{
+			{Name: "node", Args: []*InputValueDef{{Name: "id", Type: MustParseType("ID")}}, Type: MustParseType("Node")},
+			{Name: "nodes", Args: []*InputValueDef{{Name: "ids", Type: MustParseType("[ID!]!")}}, Type: MustParseType("[Node]")},
+			{Name: "search", Args: []*InputValueDef{
+				{Name: "dirs", Type: MustParseType("[Direction!]")},
+				{Name: "in", Type: MustParseType("In")},
+			}, Type: MustParseType("[Node]")},
+		}},
+		{Kind: KindInterface, Name: "Node", Fields: []*FieldDef{
+			{Name: "id", Type: MustParseType("ID!")},
+		}, PossibleTypes: []string{"User", "Post"}},
+		{Kind: KindObject, Name: "User", Interfaces: []string{"Node"}, Fields: []*FieldDef{
+			{Name: "id", Type: MustParseType("ID!")},
+			{Name: "a", Type: MustParseType("A")},
+			{Name: "b", Type: MustParseType("Int")},
+			{Name: "c", Args: []*InputValueDef{{Name: "in", Type: MustParseType("In")}}, Type: MustParseType("Int")},
+			{Name: "e", Type: MustParseType("Int")},
+		}},
+		{Kind: KindObject, Name: "Post", Interfaces: []string{"Node"}, Fields: []*FieldDef{
+			{Name: "id", Type: MustParseType("ID!")},
+			{Name: "d", Type: MustParseType("Int")},
+		}},
+		{Kind: KindObject, Name: "A", Fields: []*FieldDef{
+			{Name: "x", Type: MustParseType("Int")},
+			{Name: "y", Type: MustParseType("Int")},
+		}},
+		{Kind: KindUnion, Name: "Result", PossibleTypes: []string{"User"}},
+		{Kind: KindInputObject, Name: "In", InputFields: []*InputValueDef{
+			{Name: "name", Type: MustParseType("String")},
+			{Name: "other", Type: MustParseType("String")},
+			{Name: "dir", Type: MustParseType("Direction")},
+		}},
+		{Kind: KindEnum, Name: "Direction", EnumValues: []*EnumValueDef{{Name: "NORTH"}, {Name: "SOUTH"}}},
+	},
+})
+
+func TestRequest_CollectFields(t *testing.T) {
+	query := `query($skip: Boolean!, $name: String, $other: String) {
+	node {
+		... on User {
+			a { x }
+			a { y }
+			b @skip(if: $skip)
+			...F
+			c(in: {name: $name, other: $other})
+		}
+		... on Post { d }
+	}
+}
+
+fragment F on Result { ... on User { e } }`
+
+	r, err := NewRequest(query, "", map[string]interface{}{"skip": true, "name": "n"}, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := r.CollectFields("Query", r.Operation.Selections)[0]
+	fields := r.CollectFields("User", node.Selections)
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "e", "c"}) {
+		t.Fatalf("expected fields a, e and c but got %v", names)
+	}
+	if len(fields[0].Selections) != 2 {
+		t.Fatalf("expected merged selections of a but got %d", len(fields[0].Selections))
+	}
+	if in := fields[2].Args["in"]; !reflect.DeepEqual(in, map[string]interface{}{"name": "n"}) {
+		t.Fatalf("expected unset variables to be left out but got %#v", in)
+	}
+	if fields = r.CollectFields("Post", node.Selections); len(fields) != 1 || fields[0].Name != "d" {
+		t.Fatalf("expected field d of Post but got %v", fields)
+	}
+
+	t.Run("NonNullVariable", func(subT *testing.T) {
+		_, err := NewRequest(query, "", nil, testSchema)
+		if err == nil || err.Error() != "1:7: variable $skip of type Boolean! must not be null" {
+			subT.Fatalf("expected variable error but got %v", err)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Query string
+		Err   string
+	}{
+		{
+			Name:  "Valid",
+			Query: `query($id: ID) { node(id: $id) { id ...F } a: node { id } a: node { ... on User { b } } } fragment F on User { b }`,
+		},
+		{
+			Name:  "UnknownField",
+			Query: `{ node { name } }`,
+			Err:   `1:10: cannot query field "name" on type "Node"`,
+		},
+		{
+			Name:  "Subselection",
+			Query: `{ node }`,
+			Err:   `1:3: field "node" of type "Node" must have a selection of subfields`,
+		},
+		{
+			Name:  "LeafSelection",
+			Query: `{ node { id { x } } }`,
+			Err:   `1:10: field "id" must not have a selection since type "ID!" has no subfields`,
+		},
+		{
+			Name:  "UnknownArgument",
+			Query: `{ node(key: 1) { id } }`,
+			Err:   `1:3: unknown argument "key" on field "Query.node"`,
+		},
+		{
+			Name:  "RequiredArgument",
+			Query: `{ nodes { id } }`,
+			Err:   `1:3: argument "ids" of type "[ID!]!" is required on field "Query.nodes"`,
+		},
+		{
+			Name:  "EnumValues",
+			Query: `query($d: Direction = NORTH) { search(dirs: [SOUTH, $d], in: {dir: NORTH}) { id } }`,
+		},
+		{
+			Name:  "UnknownEnumValue",
+			Query: `{ search(dirs: [NORTH, WEST]) { id } }`,
+			Err:   `1:3: value WEST of argument "dirs" on field "Query.search" is not a value of enum "Direction"`,
+		},
+		{
+			Name:  "UnknownNestedEnumValue",
+			Query: `{ search(in: {dir: 1}) { id } }`,
+			Err:   `1:3: value 1 of argument "in" on field "Query.search" is not a value of enum "Direction"`,
+		},
+		{
+			Name:  "UnknownDefaultEnumValue",
+			Query: `query($d: [Direction] = EAST) { search(dirs: $d) { id } }`,
+			Err:   `1:7: value EAST of variable $d is not a value of enum "Direction"`,
+		},
+		{
+			Name:  "UnknownDirective",
+			Query: `{ node @defer { id } }`,
+			Err:   `1:8: unknown directive @defer`,
+		},
+		{
+			Name:  "UndefinedVariable",
+			Query: `query Q { node(id: $id) { id } }`,
+			Err:   `1:11: variable $id is not defined by operation "Q"`,
+		},
+		{
+			Name:  "UnusedVariable",
+			Query: `query Q($id: ID) { node { id } }`,
+			Err:   `1:9: variable $id is never used in operation "Q"`,
+		},
+		{
+			Name:  "UndefinedFragment",
+			Query: `{ node { ...F } }`,
+			Err:   `1:10: unknown fragment "F"`,
+		},
+		{
+			Name:  "UnusedFragment",
+			Query: `{ node { id } } fragment F on Node { id }`,
+			Err:   `1:17: fragment "F" is never used`,
+		},
+		{
+			Name:  "RecursiveFragment",
+			Query: `{ node { ...F } } fragment F on Node { ...G } fragment G on Node { ...F }`,
+			Err:   `1:40: cannot spread fragment "F" within itself via "G"`,
+		},
+		{
+			Name:  "ImpossibleFragment",
+			Query: `{ node { ... on User { ... on Post { id } } } }`,
+			Err:   `1:24: fragment cannot be spread here as objects of type "User" can never be of type "Post"`,
+		},
+		{
+			Name:  "ConflictingArgs",
+			Query: `{ node(id: 1) { id } node(id: 2) { id } }`,
+			Err:   `1:3: fields "node" conflict because they have differing arguments`,
+		},
+		{
+			Name:  "ConflictingFields",
+			Query: `{ node { x: id ... on User { x: b } } }`,
+			Err:   `1:10: fields "x" conflict because "id" and "b" are different fields`,
+		},
+		{
+			Name:  "ConflictingTypes",
+			Query: `{ node { ... on User { x: id } ... on Post { x: d } } }`,
+			Err:   `1:24: fields "x" conflict because they return conflicting types "ID!" and "Int"`,
+		},
+		{
+			Name:  "ConflictingSubfields",
+			Query: `{ node { ... on User { a { x } } ... on User { a { x: y } } } }`,
+			Err:   `1:28: fields "x" conflict because "x" and "y" are different fields`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			doc, err := Parse(testCase.Query)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			err = Validate(testSchema, doc)
+			if testCase.Err == "" {
+				if err != nil {
+					subT.Fatalf("expected no error but got %v", err)
+				}
+				return
+			}
+			if err == nil || err.(Errors)[0].Error() != testCase.Err {
+				subT.Fatalf("expected %s but got %v", testCase.Err, err)
+			}
+		})
+	}
+}
+
+func TestRequest_Introspect(t *testing.T) {
+	query := `{
+	__schema { queryType { name } mutationType { name } directives { name } }
+	__type(name: "User") {
+		kind
+		name
+		interfaces { name }
+		fields { name args { name defaultValue } type { kind name ofType { kind name } } }
+	}
+	missing: __type(name: "Missing") { name }
+}`
+
+	r, err := NewRequest(query, "", nil, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := r.CollectFields("Query", r.Operation.Selections)
+	data := NewObject(len(fields))
+	for _, f := range fields {
+		data.Set(f.Alias, r.Introspect(f))
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ex := `{"__schema":{"queryType":{"name":"Query"},"mutationType":null,"directives":[{"name":"include"},{"name":"skip"},{"name":"deprecated"}]},` +
+		`"__type":{"kind":"OBJECT","name":"User","interfaces":[{"name":"Node"}],"fields":[` +
+		`{"name":"id","args":[],"type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"ID"}}},` +
+		`{"name":"a","args":[],"type":{"kind":"OBJECT","name":"A","ofType":null}},` +
+		`{"name":"b","args":[],"type":{"kind":"SCALAR","name":"Int","ofType":null}},` +
+		`{"name":"c","args":[{"name":"in","defaultValue":null}],"type":{"kind":"SCALAR","name":"Int","ofType":null}},` +
+		`{"name":"e","args":[],"type":{"kind":"SCALAR","name":"Int","ofType":null}}]},` +
+		`"missing":null}`
+	if string(b) != ex {
+		t.Fatalf("expected %s but got %s", ex, b)
+	}
+
+	t.Run("NotQueryType", func(subT *testing.T) {
+		_, err := NewRequest(`{ node { __schema { types { name } } } }`, "", nil, testSchema)
+		if err == nil || err.Error() != `1:10: cannot query field "__schema" on type "Node"` {
+			subT.Fatalf("expected validation error but got %v", err)
+		}
+	})
+}
+
+func TestResponse_MarshalJSON(t *testing.T) {
+	r, err := NewRequest("{ node { id } }", "", nil, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(r.Response(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex := `{"data":null}`; string(b) != ex {
+		t.Fatalf("expected %s but got %s", ex, b)
+	}
+
+	t.Run("NotExecuted", func(subT *testing.T) {
+		_, err := NewRequest("{ node { ...F } }", "", nil, testSchema)
+		b, err := json.Marshal(ErrorResponse(err))
+		if err != nil {
+			subT.Fatal(err)
+		}
+
+		ex := `{"errors":[{"message":"unknown fragment \"F\"","locations":[{"line":1,"column":10}]}]}`
+		if string(b) != ex {
+			subT.Fatalf("expected %s but got %s", ex, b)
+		}
+	})
+}
+
+func TestObject_MarshalJSON(t *testing.T) {
+	inner := NewObject(1)
+	inner.Set("s", "<\"a\">")
+
+	o := NewObject(4)
+	o.Set("b", 1)
+	o.Set("a", []interface{}{true, nil, inner})
+	o.Set("f", 1.5)
+	o.Set("o", (*Object)(nil))
+
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ex := `{"b":1,"a":[true,null,{"s":"\u003c\"a\"\u003e"}],"f":1.5,"o":null}`
+	if string(b) != ex {
+		t.Fatalf("expected %s but got %s", ex, b)
+	}
+}
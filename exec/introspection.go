@@ -0,0 +1,410 @@
+package exec
+
+// Meta fields, which are selected on the query type, or any type in the case of __typename.
+var (
+	schemaField = &FieldDef{
+		Name:        "__schema",
+		Description: "Access the current type schema of this server.",
+		Type:        MustParseType("__Schema!"),
+	}
+	typeField = &FieldDef{
+		Name:        "__type",
+		Description: "Request the type information of a single type.",
+		Args:        []*InputValueDef{{Name: "name", Type: MustParseType("String!")}},
+		Type:        MustParseType("__Type"),
+	}
+	typenameField = &FieldDef{
+		Name:        "__typename",
+		Description: "The name of the current Object type at runtime.",
+		Type:        MustParseType("String!"),
+	}
+)
+
+// includeDeprecated is the argument of fields listing fields or enum values, which may be deprecated.
+var includeDeprecated = []*InputValueDef{{Name: "includeDeprecated", Type: MustParseType("Boolean"), DefaultValue: "false"}}
+
+// introspectionTypes are the types of the introspection system, which every schema contains.
+var introspectionTypes = []*TypeDef{
+	{
+		Kind:        KindObject,
+		Name:        "__Schema",
+		Description: "A GraphQL Schema defines the capabilities of a GraphQL server. It exposes all available types and directives on the server, as well as the entry points for query, mutation, and subscription operations.",
+		Fields: []*FieldDef{
+			{Name: "types", Description: "A list of all types supported by this server.", Type: MustParseType("[__Type!]!")},
+			{Name: "queryType", Description: "The type that query operations will be rooted at.", Type: MustParseType("__Type!")},
+			{Name: "mutationType", Description: "If this server supports mutation, the type that mutation operations will be rooted at.", Type: MustParseType("__Type")},
+			{Name: "subscriptionType", Description: "If this server support subscription, the type that subscription operations will be rooted at.", Type: MustParseType("__Type")},
+			{Name: "directives", Description: "A list of all directives supported by this server.", Type: MustParseType("[__Directive!]!")},
+		},
+	},
+	{
+		Kind:        KindObject,
+		Name:        "__Type",
+		Description: "The fundamental unit of any GraphQL Schema is the type. There are many kinds of types in GraphQL as represented by the `__TypeKind` enum.\n\nDepending on the kind of a type, certain fields describe information about that type. Scalar types provide no information beyond a name and description, while Enum types provide their values. Object and Interface types provide the fields they describe. Abstract types, Union and Interface, provide the Object types possible at runtime. List and NonNull types compose other types.",
+		Fields: []*FieldDef{
+			{Name: "kind", Type: MustParseType("__TypeKind!")},
+			{Name: "name", Type: MustParseType("String")},
+			{Name: "description", Type: MustParseType("String")},
+			{Name: "fields", Args: includeDeprecated, Type: MustParseType("[__Field!]")},
+			{Name: "interfaces", Type: MustParseType("[__Type!]")},
+			{Name: "possibleTypes", Type: MustParseType("[__Type!]")},
+			{Name: "enumValues", Args: includeDeprecated, Type: MustParseType("[__EnumValue!]")},
+			{Name: "inputFields", Type: MustParseType("[__InputValue!]")},
+			{Name: "ofType", Type: MustParseType("__Type")},
+		},
+	},
+	{
+		Kind:        KindObject,
+		Name:        "__Field",
+		Description: "Object and Interface types are described by a list of Fields, each of which has a name, potentially a list of arguments, and a return type.",
+		Fields: []*FieldDef{
+			{Name: "name", Type: MustParseType("String!")},
+			{Name: "description", Type: MustParseType("String")},
+			{Name: "args", Type: MustParseType("[__InputValue!]!")},
+			{Name: "type", Type: MustParseType("__Type!")},
+			{Name: "isDeprecated", Type: MustParseType("Boolean!")},
+			{Name: "deprecationReason", Type: MustParseType("String")},
+		},
+	},
+	{
+		Kind:        KindObject,
+		Name:        "__InputValue",
+		Description: "Arguments provided to Fields or Directives and the input fields of an InputObject are represented as Input Values which describe their type and optionally a default value.",
+		Fields: []*FieldDef{
+			{Name: "name", Type: MustParseType("String!")},
+			{Name: "description", Type: MustParseType("String")},
+			{Name: "type", Type: MustParseType("__Type!")},
+			{Name: "defaultValue", Description: "A GraphQL-formatted string representing the default value for this input value.", Type: MustParseType("String")},
+		},
+	},
+	{
+		Kind:        KindObject,
+		Name:        "__EnumValue",
+		Description: "One possible value for a given Enum. Enum values are unique values, not a placeholder for a string or numeric value. However an Enum value is returned in a JSON response as a string.",
+		Fields: []*FieldDef{
+			{Name: "name", Type: MustParseType("String!")},
+			{Name: "description", Type: MustParseType("String")},
+			{Name: "isDeprecated", Type: MustParseType("Boolean!")},
+			{Name: "deprecationReason", Type: MustParseType("String")},
+		},
+	},
+	{
+		Kind:        KindObject,
+		Name:        "__Directive",
+		Description: "A Directive provides a way to describe alternate runtime execution and type validation behavior in a GraphQL document.\n\nIn some cases, you need to provide options to alter GraphQL's execution behavior in ways field arguments will not suffice, such as conditionally including or skipping a field. Directives provide this by describing additional information to the executor.",
+		Fields: []*FieldDef{
+			{Name: "name", Type: MustParseType("String!")},
+			{Name: "description", Type: MustParseType("String")},
+			{Name: "locations", Type: MustParseType("[__DirectiveLocation!]!")},
+			{Name: "args", Type: MustParseType("[__InputValue!]!")},
+		},
+	},
+	{
+		Kind:        KindEnum,
+		Name:        "__TypeKind",
+		Description: "An enum describing what kind of type a given `__Type` is.",
+		EnumValues: []*EnumValueDef{
+			{Name: KindScalar, Description: "Indicates this type is a scalar."},
+			{Name: KindObject, Description: "Indicates this type is an object. `fields` and `interfaces` are valid fields."},
+			{Name: KindInterface, Description: "Indicates this type is an interface. `fields` and `possibleTypes` are valid fields."},
+			{Name: KindUnion, Description: "Indicates this type is a union. `possibleTypes` is a valid field."},
+			{Name: KindEnum, Description: "Indicates this type is an enum. `enumValues` is a valid field."},
+			{Name: KindInputObject, Description: "Indicates this type is an input object. `inputFields` is a valid field."},
+			{Name: KindList, Description: "Indicates this type is a list. `ofType` is a valid field."},
+			{Name: KindNonNull, Description: "Indicates this type is a non-null. `ofType` is a valid field."},
+		},
+	},
+	{
+		Kind:        KindEnum,
+		Name:        "__DirectiveLocation",
+		Description: "A Directive can be adjacent to many parts of the GraphQL language, a __DirectiveLocation describes one such possible adjacencies.",
+		EnumValues: []*EnumValueDef{
+			{Name: "QUERY", Description: "Location adjacent to a query operation."},
+			{Name: "MUTATION", Description: "Location adjacent to a mutation operation."},
+			{Name: "SUBSCRIPTION", Description: "Location adjacent to a subscription operation."},
+			{Name: "FIELD", Description: "Location adjacent to a field."},
+			{Name: "FRAGMENT_DEFINITION", Description: "Location adjacent to a fragment definition."},
+			{Name: "FRAGMENT_SPREAD", Description: "Location adjacent to a fragment spread."},
+			{Name: "INLINE_FRAGMENT", Description: "Location adjacent to an inline fragment."},
+			{Name: "VARIABLE_DEFINITION", Description: "Location adjacent to a variable definition."},
+			{Name: "SCHEMA", Description: "Location adjacent to a schema definition."},
+			{Name: "SCALAR", Description: "Location adjacent to a scalar definition."},
+			{Name: "OBJECT", Description: "Location adjacent to an object type definition."},
+			{Name: "FIELD_DEFINITION", Description: "Location adjacent to a field definition."},
+			{Name: "ARGUMENT_DEFINITION", Description: "Location adjacent to an argument definition."},
+			{Name: "INTERFACE", Description: "Location adjacent to an interface definition."},
+			{Name: "UNION", Description: "Location adjacent to a union definition."},
+			{Name: "ENUM", Description: "Location adjacent to an enum definition."},
+			{Name: "ENUM_VALUE", Description: "Location adjacent to an enum value definition."},
+			{Name: "INPUT_OBJECT", Description: "Location adjacent to an input object type definition."},
+			{Name: "INPUT_FIELD_DEFINITION", Description: "Location adjacent to an input object field definition."},
+		},
+	},
+}
+
+// metaField returns the definition of a meta field selected on the given type, if any.
+func (s *Schema) metaField(parent *TypeDef, name string) *FieldDef {
+	switch {
+	case name == typenameField.Name:
+		return typenameField
+	case parent.Name != s.Query:
+		return nil
+	case name == schemaField.Name:
+		return schemaField
+	case name == typeField.Name:
+		return typeField
+	}
+	return nil
+}
+
+// Introspect resolves the __schema or __type field, which are selected on the query type.
+func (r *Request) Introspect(f *Field) interface{} {
+	switch f.Name {
+	case schemaField.Name:
+		return r.introspect("__Schema", r.schema, f.Selections)
+	case typeField.Name:
+		name, _ := f.Args["name"].(string)
+		if t := r.schema.Type(name); t != nil {
+			return r.introspect("__Type", t, f.Selections)
+		}
+	}
+	return nil
+}
+
+// introspect executes a selection set on a value of an introspection type. Values of
+// __Type are either a *TypeDef for named types, or a *Type for lists and non-null types.
+func (r *Request) introspect(typ string, v interface{}, sels []Selection) interface{} {
+	fields := r.CollectFields(typ, sels)
+	res := NewObject(len(fields))
+	for _, f := range fields {
+		if f.Name == typenameField.Name {
+			res.Set(f.Alias, typ)
+			continue
+		}
+
+		var val interface{}
+		switch v := v.(type) {
+		case *Schema:
+			val = r.introspectSchema(v, f)
+		case *TypeDef:
+			val = r.introspectTypeDef(v, f)
+		case *Type:
+			val = r.introspectType(v, f)
+		case *FieldDef:
+			val = r.introspectField(v, f)
+		case *InputValueDef:
+			val = r.introspectInputValue(v, f)
+		case *EnumValueDef:
+			val = introspectEnumValue(v, f)
+		case *DirectiveDef:
+			val = r.introspectDirective(v, f)
+		}
+		res.Set(f.Alias, val)
+	}
+	return res
+}
+
+// typeRef returns the __Type of a type reference.
+func (r *Request) typeRef(t *Type, sels []Selection) interface{} {
+	if t.NonNull || t.Elem != nil {
+		return r.introspect("__Type", t, sels)
+	}
+	if def := r.schema.Type(t.Name); def != nil {
+		return r.introspect("__Type", def, sels)
+	}
+	return nil
+}
+
+// namedType returns the __Type of the named type, or nil if there's no such type.
+func (r *Request) namedType(name string, sels []Selection) interface{} {
+	if t := r.schema.Type(name); t != nil {
+		return r.introspect("__Type", t, sels)
+	}
+	return nil
+}
+
+// namedTypes returns the __Types of the named types.
+func (r *Request) namedTypes(names []string, sels []Selection) []interface{} {
+	l := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		if t := r.namedType(name, sels); t != nil {
+			l = append(l, t)
+		}
+	}
+	return l
+}
+
+// inputValues returns the __InputValues of arguments or input fields.
+func (r *Request) inputValues(defs []*InputValueDef, sels []Selection) []interface{} {
+	l := make([]interface{}, len(defs))
+	for i, def := range defs {
+		l[i] = r.introspect("__InputValue", def, sels)
+	}
+	return l
+}
+
+func (r *Request) introspectSchema(s *Schema, f *Field) interface{} {
+	switch f.Name {
+	case "types":
+		l := make([]interface{}, len(s.Types))
+		for i, t := range s.Types {
+			l[i] = r.introspect("__Type", t, f.Selections)
+		}
+		return l
+	case "queryType":
+		return r.namedType(s.Query, f.Selections)
+	case "mutationType":
+		return r.namedType(s.Mutation, f.Selections)
+	case "subscriptionType":
+		return r.namedType(s.Subscription, f.Selections)
+	case "directives":
+		l := make([]interface{}, len(s.Directives))
+		for i, d := range s.Directives {
+			l[i] = r.introspect("__Directive", d, f.Selections)
+		}
+		return l
+	}
+	return nil
+}
+
+func (r *Request) introspectTypeDef(t *TypeDef, f *Field) interface{} {
+	switch f.Name {
+	case "kind":
+		return t.Kind
+	case "name":
+		return t.Name
+	case "description":
+		return optional(t.Description)
+	case "fields":
+		if t.Kind != KindObject && t.Kind != KindInterface {
+			return nil
+		}
+
+		all, _ := f.Args["includeDeprecated"].(bool)
+		l := make([]interface{}, 0, len(t.Fields))
+		for _, def := range t.Fields {
+			if all || !def.Deprecated {
+				l = append(l, r.introspect("__Field", def, f.Selections))
+			}
+		}
+		return l
+	case "interfaces":
+		if t.Kind != KindObject {
+			return nil
+		}
+		return r.namedTypes(t.Interfaces, f.Selections)
+	case "possibleTypes":
+		if t.Kind != KindInterface && t.Kind != KindUnion {
+			return nil
+		}
+		return r.namedTypes(t.PossibleTypes, f.Selections)
+	case "enumValues":
+		if t.Kind != KindEnum {
+			return nil
+		}
+
+		all, _ := f.Args["includeDeprecated"].(bool)
+		l := make([]interface{}, 0, len(t.EnumValues))
+		for _, def := range t.EnumValues {
+			if all || !def.Deprecated {
+				l = append(l, r.introspect("__EnumValue", def, f.Selections))
+			}
+		}
+		return l
+	case "inputFields":
+		if t.Kind != KindInputObject {
+			return nil
+		}
+		return r.inputValues(t.InputFields, f.Selections)
+	}
+	return nil
+}
+
+// introspectType resolves the fields of lists and non-null types, which wrap another type.
+func (r *Request) introspectType(t *Type, f *Field) interface{} {
+	switch f.Name {
+	case "kind":
+		if t.NonNull {
+			return KindNonNull
+		}
+		return KindList
+	case "ofType":
+		if t.NonNull {
+			return r.typeRef(&Type{Name: t.Name, Elem: t.Elem}, f.Selections)
+		}
+		return r.typeRef(t.Elem, f.Selections)
+	}
+	return nil
+}
+
+func (r *Request) introspectField(def *FieldDef, f *Field) interface{} {
+	switch f.Name {
+	case "name":
+		return def.Name
+	case "description":
+		return optional(def.Description)
+	case "args":
+		return r.inputValues(def.Args, f.Selections)
+	case "type":
+		return r.typeRef(def.Type, f.Selections)
+	case "isDeprecated":
+		return def.Deprecated
+	case "deprecationReason":
+		return optional(def.DeprecationReason)
+	}
+	return nil
+}
+
+func (r *Request) introspectInputValue(def *InputValueDef, f *Field) interface{} {
+	switch f.Name {
+	case "name":
+		return def.Name
+	case "description":
+		return optional(def.Description)
+	case "type":
+		return r.typeRef(def.Type, f.Selections)
+	case "defaultValue":
+		return optional(def.DefaultValue)
+	}
+	return nil
+}
+
+func introspectEnumValue(def *EnumValueDef, f *Field) interface{} {
+	switch f.Name {
+	case "name":
+		return def.Name
+	case "description":
+		return optional(def.Description)
+	case "isDeprecated":
+		return def.Deprecated
+	case "deprecationReason":
+		return optional(def.DeprecationReason)
+	}
+	return nil
+}
+
+func (r *Request) introspectDirective(def *DirectiveDef, f *Field) interface{} {
+	switch f.Name {
+	case "name":
+		return def.Name
+	case "description":
+		return optional(def.Description)
+	case "locations":
+		l := make([]interface{}, len(def.Locations))
+		for i, loc := range def.Locations {
+			l[i] = loc
+		}
+		return l
+	case "args":
+		return r.inputValues(def.Args, f.Selections)
+	}
+	return nil
+}
+
+// optional returns nil for empty strings, which are null in introspection.
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,631 @@
+package exec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is a parsed query document.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a query, mutation or subscription.
+type Operation struct {
+	// Type is the type of the operation i.e. query, mutation or subscription
+	Type string
+
+	Name       string
+	Vars       []*VarDef
+	Directives []*Directive
+	Selections []Selection
+	Loc        Location
+}
+
+// VarDef is the definition of an operations' variable.
+type VarDef struct {
+	Name       string
+	Type       *Type
+	Default    interface{}
+	HasDefault bool
+	Loc        Location
+}
+
+// Type is the type of a variable. Lists have an element type, while anything else has a name.
+type Type struct {
+	Name    string
+	Elem    *Type
+	NonNull bool
+}
+
+// String returns the type as it's written in GraphQL.
+func (t *Type) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Location is the position of a token in a query, starting at 1.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Selection is a Field, FragmentSpread or InlineFragment.
+type Selection interface {
+	isSelection()
+}
+
+// Field is a selected field. Its arguments are Go values i.e. nil, bool, int, float64,
+// string, []interface{} or map[string]interface{}, where enum values are strings and
+// variables are a Variable.
+type Field struct {
+	Alias      string
+	Name       string
+	Args       map[string]interface{}
+	Directives []*Directive
+	Selections []Selection
+	Loc        Location
+}
+
+// FragmentSpread is a selected fragment.
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Loc        Location
+}
+
+// InlineFragment is a selection set which only applies to the given type, if any.
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Loc           Location
+}
+
+func (*Field) isSelection()          {}
+func (*FragmentSpread) isSelection() {}
+func (*InlineFragment) isSelection() {}
+
+// Fragment is the definition of a fragment.
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Loc           Location
+}
+
+// Directive is a directive applied to an operation or selection.
+type Directive struct {
+	Name string
+	Args map[string]interface{}
+	Loc  Location
+}
+
+// Variable is a reference to a variable in an argument.
+type Variable string
+
+// Parse parses a query document.
+func Parse(query string) (doc *Document, err error) {
+	p := &parser{lexer: lexer{src: strings.TrimPrefix(query, "\ufeff"), line: 1}}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			err = perr
+		}
+	}()
+
+	p.next()
+	doc = &Document{Fragments: make(map[string]*Fragment)}
+	for p.tok.kind != tokEOF {
+		if p.tok.kind == tokPunct && p.tok.val == "{" {
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", Loc: p.tok.loc, Selections: p.selectionSet()})
+			continue
+		}
+
+		switch p.expect(tokName).val {
+		case "query", "mutation", "subscription":
+			doc.Operations = append(doc.Operations, p.operation())
+		case "fragment":
+			frag := p.fragment()
+			if _, ok := doc.Fragments[frag.Name]; ok {
+				p.fail(frag.Loc, "there can be only one fragment named %q", frag.Name)
+			}
+			doc.Fragments[frag.Name] = frag
+		default:
+			p.fail(p.tok.loc, "unexpected %s", p.tok)
+		}
+	}
+	if len(doc.Operations) == 0 {
+		p.fail(p.tok.loc, "no operations in query document")
+	}
+	return
+}
+
+// Token kinds
+const (
+	tokEOF = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type tok struct {
+	kind int
+	val  string
+	loc  Location
+}
+
+func (t tok) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "<EOF>"
+	case tokString:
+		return strconv.Quote(t.val)
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// lexer splits a query into tokens, skipping whitespace, commas and comments.
+type lexer struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+}
+
+func (l *lexer) fail(format string, args ...interface{}) {
+	panic(&Error{
+		Message:   "syntax error: " + fmt.Sprintf(format, args...),
+		Locations: []Location{{Line: l.line, Column: l.pos - l.lineStart + 1}},
+	})
+}
+
+func (l *lexer) newLine() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+func (l *lexer) lex() tok {
+	// Skip ignored tokens
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if c == '\n' || (c == '\r' && !strings.HasPrefix(l.src[l.pos:], "\r\n")) {
+			l.pos++
+			l.newLine()
+			continue
+		}
+		if c != ' ' && c != '\t' && c != ',' && c != '\r' {
+			break
+		}
+		l.pos++
+	}
+
+	t := tok{loc: Location{Line: l.line, Column: l.pos - l.lineStart + 1}}
+	if l.pos == len(l.src) {
+		return t
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		t.kind, t.val = tokPunct, "..."
+	case strings.IndexByte("!$&()/:=@[]{|}", c) >= 0:
+		l.pos++
+		t.kind, t.val = tokPunct, string(c)
+	case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		for l.pos < len(l.src) && isNameChar(l.src[l.pos]) {
+			l.pos++
+		}
+		t.kind, t.val = tokName, l.src[start:l.pos]
+	case c == '-' || '0' <= c && c <= '9':
+		t.kind = l.number()
+		t.val = l.src[start:l.pos]
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		t.kind, t.val = tokString, l.blockString()
+	case c == '"':
+		t.kind, t.val = tokString, l.string()
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+		l.fail("unexpected character %q", r)
+	}
+	return t
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func (l *lexer) digits() {
+	start := l.pos
+	for l.pos < len(l.src) && '0' <= l.src[l.pos] && l.src[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos == start {
+		l.fail("expected digit")
+	}
+}
+
+func (l *lexer) number() int {
+	kind := tokInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '0' {
+		l.pos++
+	} else {
+		l.digits()
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokFloat
+		l.pos++
+		l.digits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		l.digits()
+	}
+	if l.pos < len(l.src) && (isNameChar(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.fail("invalid number")
+	}
+	return kind
+}
+
+func (l *lexer) string() string {
+	var b strings.Builder
+	l.pos++
+	for {
+		if l.pos == len(l.src) || l.src[l.pos] == '\n' || l.src[l.pos] == '\r' {
+			l.fail("unterminated string")
+		}
+
+		c := l.src[l.pos]
+		l.pos++
+		switch c {
+		case '"':
+			return b.String()
+		case '\\':
+			if l.pos == len(l.src) {
+				l.fail("unterminated string")
+			}
+			esc := l.src[l.pos]
+			l.pos++
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					l.fail("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					l.fail("invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				l.pos += 4
+			default:
+				l.fail("invalid escape \\%c", esc)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (l *lexer) blockString() string {
+	l.pos += 3
+	start := l.pos
+	for {
+		if l.pos == len(l.src) {
+			l.fail("unterminated block string")
+		}
+		if strings.HasPrefix(l.src[l.pos:], `\"""`) {
+			l.pos += 4
+			continue
+		}
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			raw := l.src[start:l.pos]
+			l.pos += 3
+			return blockStringValue(raw)
+		}
+		if l.src[l.pos] == '\n' || (l.src[l.pos] == '\r' && !strings.HasPrefix(l.src[l.pos:], "\r\n")) {
+			l.pos++
+			l.newLine()
+			continue
+		}
+		l.pos++
+	}
+}
+
+// blockStringValue returns the value of a block string, given its raw contents.
+func blockStringValue(raw string) string {
+	raw = strings.Replace(raw, `\"""`, `"""`, -1)
+	raw = strings.Replace(raw, "\r\n", "\n", -1)
+	lines := strings.Split(strings.Replace(raw, "\r", "\n", -1), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if n < len(line) && (indent < 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i, line := range lines[1:] {
+			if len(line) < indent {
+				lines[i+1] = ""
+				continue
+			}
+			lines[i+1] = line[indent:]
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parser parses the executable definitions of a query document.
+type parser struct {
+	lexer
+	tok tok
+}
+
+func (p *parser) fail(loc Location, format string, args ...interface{}) {
+	panic(&Error{Message: "syntax error: " + fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (p *parser) next() tok {
+	t := p.tok
+	p.tok = p.lex()
+	return t
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.val == punct
+}
+
+func (p *parser) skip(punct string) bool {
+	if p.peek(punct) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind int) tok {
+	if p.tok.kind != kind {
+		p.fail(p.tok.loc, "unexpected %s", p.tok)
+	}
+	return p.tok
+}
+
+func (p *parser) expectPunct(punct string) {
+	if !p.skip(punct) {
+		p.fail(p.tok.loc, "expected %q but got %s", punct, p.tok)
+	}
+}
+
+func (p *parser) name() string {
+	return p.next().val
+}
+
+func (p *parser) operation() *Operation {
+	op := &Operation{Loc: p.tok.loc, Type: p.name()}
+	if p.tok.kind == tokName {
+		op.Name = p.name()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			v := &VarDef{Loc: p.tok.loc}
+			p.expectPunct("$")
+			p.expect(tokName)
+			v.Name = p.name()
+			p.expectPunct(":")
+			v.Type = p.typ()
+			if p.skip("=") {
+				v.Default, v.HasDefault = p.value(true), true
+			}
+			op.Vars = append(op.Vars, v)
+		}
+	}
+	op.Directives = p.directives()
+	op.Selections = p.selectionSet()
+	return op
+}
+
+func (p *parser) fragment() *Fragment {
+	frag := &Fragment{Loc: p.tok.loc}
+	p.next()
+	p.expect(tokName)
+	frag.Name = p.name()
+	if frag.Name == "on" {
+		p.fail(frag.Loc, "unexpected \"on\"")
+	}
+	if p.expect(tokName).val != "on" {
+		p.fail(p.tok.loc, "expected \"on\" but got %s", p.tok)
+	}
+	p.next()
+	p.expect(tokName)
+	frag.TypeCondition = p.name()
+	frag.Directives = p.directives()
+	frag.Selections = p.selectionSet()
+	return frag
+}
+
+func (p *parser) typ() *Type {
+	t := new(Type)
+	if p.skip("[") {
+		t.Elem = p.typ()
+		p.expectPunct("]")
+	} else {
+		p.expect(tokName)
+		t.Name = p.name()
+	}
+	t.NonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) directives() (dirs []*Directive) {
+	for p.peek("@") {
+		d := &Directive{Loc: p.tok.loc}
+		p.next()
+		p.expect(tokName)
+		d.Name = p.name()
+		d.Args = p.arguments()
+		dirs = append(dirs, d)
+	}
+	return
+}
+
+func (p *parser) arguments() map[string]interface{} {
+	if !p.skip("(") {
+		return nil
+	}
+
+	args := make(map[string]interface{})
+	for !p.skip(")") {
+		p.expect(tokName)
+		name := p.name()
+		p.expectPunct(":")
+		args[name] = p.value(false)
+	}
+	return args
+}
+
+func (p *parser) selectionSet() (sels []Selection) {
+	p.expectPunct("{")
+	for !p.skip("}") {
+		loc := p.tok.loc
+		if p.skip("...") {
+			if p.tok.kind == tokName && p.tok.val != "on" {
+				sels = append(sels, &FragmentSpread{Name: p.name(), Directives: p.directives(), Loc: loc})
+				continue
+			}
+
+			frag := &InlineFragment{Loc: loc}
+			if p.tok.kind == tokName {
+				p.next()
+				p.expect(tokName)
+				frag.TypeCondition = p.name()
+			}
+			frag.Directives = p.directives()
+			frag.Selections = p.selectionSet()
+			sels = append(sels, frag)
+			continue
+		}
+
+		p.expect(tokName)
+		f := &Field{Loc: loc, Name: p.name()}
+		if p.skip(":") {
+			p.expect(tokName)
+			f.Alias, f.Name = f.Name, p.name()
+		}
+		f.Args = p.arguments()
+		f.Directives = p.directives()
+		if p.peek("{") {
+			f.Selections = p.selectionSet()
+		}
+		if f.Alias == "" {
+			f.Alias = f.Name
+		}
+		sels = append(sels, f)
+	}
+	return
+}
+
+func (p *parser) value(constant bool) interface{} {
+	t := p.next()
+	switch t.kind {
+	case tokInt:
+		n, err := strconv.Atoi(t.val)
+		if err != nil {
+			p.fail(t.loc, "invalid Int %s", t.val)
+		}
+		return n
+	case tokFloat:
+		f, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			p.fail(t.loc, "invalid Float %s", t.val)
+		}
+		return f
+	case tokString:
+		return t.val
+	case tokName:
+		switch t.val {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+		return t.val
+	case tokPunct:
+		switch t.val {
+		case "$":
+			if constant {
+				p.fail(t.loc, "unexpected variable")
+			}
+			p.expect(tokName)
+			return Variable(p.name())
+		case "[":
+			l := []interface{}{}
+			for !p.skip("]") {
+				l = append(l, p.value(constant))
+			}
+			return l
+		case "{":
+			m := make(map[string]interface{})
+			for !p.skip("}") {
+				p.expect(tokName)
+				name := p.name()
+				p.expectPunct(":")
+				m[name] = p.value(constant)
+			}
+			return m
+		}
+	}
+	p.fail(t.loc, "unexpected %s", t)
+	return nil
+}
@@ -0,0 +1,234 @@
+package exec
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Kinds of types, as named by introspection.
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+	KindList        = "LIST"
+	KindNonNull     = "NON_NULL"
+)
+
+// DefaultDeprecationReason is the reason of deprecations, which don't give one.
+const DefaultDeprecationReason = "No longer supported"
+
+// Schema describes the types of a schema, which requests are validated against.
+type Schema struct {
+	// Query, Mutation and Subscription are the names of the root operation types
+	Query        string
+	Mutation     string
+	Subscription string
+
+	Types      []*TypeDef
+	Directives []*DirectiveDef
+
+	types map[string]*TypeDef
+}
+
+// TypeDef describes a named type.
+type TypeDef struct {
+	Kind        string
+	Name        string
+	Description string
+
+	// Fields and Interfaces are those of objects and interfaces
+	Fields     []*FieldDef
+	Interfaces []string
+
+	// PossibleTypes are the object types of interfaces and unions
+	PossibleTypes []string
+
+	EnumValues  []*EnumValueDef
+	InputFields []*InputValueDef
+}
+
+// FieldDef describes a field of an object or interface.
+type FieldDef struct {
+	Name              string
+	Description       string
+	Args              []*InputValueDef
+	Type              *Type
+	Deprecated        bool
+	DeprecationReason string
+}
+
+// InputValueDef describes an argument or input field. Its default value, if any,
+// is given as a GraphQL literal.
+type InputValueDef struct {
+	Name         string
+	Description  string
+	Type         *Type
+	DefaultValue string
+}
+
+// EnumValueDef describes an enum value.
+type EnumValueDef struct {
+	Name              string
+	Description       string
+	Deprecated        bool
+	DeprecationReason string
+}
+
+// DirectiveDef describes a directive.
+type DirectiveDef struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*InputValueDef
+}
+
+// builtinScalars are the scalars every schema contains.
+var builtinScalars = []*TypeDef{
+	{Kind: KindScalar, Name: "Int", Description: "The `Int` scalar type represents non-fractional signed whole numeric values."},
+	{Kind: KindScalar, Name: "Float", Description: "The `Float` scalar type represents signed double-precision fractional values."},
+	{Kind: KindScalar, Name: "String", Description: "The `String` scalar type represents textual data, represented as UTF-8 character sequences."},
+	{Kind: KindScalar, Name: "Boolean", Description: "The `Boolean` scalar type represents `true` or `false`."},
+	{Kind: KindScalar, Name: "ID", Description: "The `ID` scalar type represents a unique identifier."},
+}
+
+// builtinDirectives are the directives every schema contains.
+var builtinDirectives = []*DirectiveDef{
+	{
+		Name:        "include",
+		Description: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*InputValueDef{{Name: "if", Description: "Included when true.", Type: MustParseType("Boolean!")}},
+	},
+	{
+		Name:        "skip",
+		Description: "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*InputValueDef{{Name: "if", Description: "Skipped when true.", Type: MustParseType("Boolean!")}},
+	},
+	{
+		Name:        "deprecated",
+		Description: "Marks an element of a GraphQL schema as no longer supported.",
+		Locations:   []string{"FIELD_DEFINITION", "ENUM_VALUE"},
+		Args:        []*InputValueDef{{Name: "reason", Description: "Explains why this element was deprecated.", Type: MustParseType("String"), DefaultValue: strconv.Quote(DefaultDeprecationReason)}},
+	},
+}
+
+// NewSchema returns a schema with the given types and directives, along with the
+// builtin scalars and directives, unless they're declared, and the introspection types.
+func NewSchema(s Schema) *Schema {
+	s.types = make(map[string]*TypeDef, len(s.Types)+len(builtinScalars)+len(introspectionTypes))
+	for _, t := range s.Types {
+		s.types[t.Name] = t
+	}
+
+	s.Types = s.Types[:len(s.Types):len(s.Types)]
+	for _, t := range builtinScalars {
+		if _, ok := s.types[t.Name]; !ok {
+			s.types[t.Name] = t
+			s.Types = append(s.Types, t)
+		}
+	}
+	for _, t := range introspectionTypes {
+		s.types[t.Name] = t
+		s.Types = append(s.Types, t)
+	}
+
+	declared := make(map[string]bool, len(s.Directives))
+	for _, d := range s.Directives {
+		declared[d.Name] = true
+	}
+	for _, d := range builtinDirectives {
+		if !declared[d.Name] {
+			s.Directives = append(s.Directives, d)
+		}
+	}
+	return &s
+}
+
+// Type returns the type with the given name, or nil if there's none.
+func (s *Schema) Type(name string) *TypeDef {
+	return s.types[name]
+}
+
+// Directive returns the directive with the given name, or nil if there's none.
+func (s *Schema) Directive(name string) *DirectiveDef {
+	for _, d := range s.Directives {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// rootType returns the root operation type of the given operation type, if any.
+func (s *Schema) rootType(op string) *TypeDef {
+	switch op {
+	case "query":
+		return s.types[s.Query]
+	case "mutation":
+		return s.types[s.Mutation]
+	case "subscription":
+		return s.types[s.Subscription]
+	}
+	return nil
+}
+
+// Field returns the field with the given name, or nil if there's none.
+func (t *TypeDef) Field(name string) *FieldDef {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// enumValue returns the enum value with the given name, or nil if there's none.
+func (t *TypeDef) enumValue(name string) *EnumValueDef {
+	for _, ev := range t.EnumValues {
+		if ev.Name == name {
+			return ev
+		}
+	}
+	return nil
+}
+
+// isComposite reports whether selection sets can be selected on the type.
+func (t *TypeDef) isComposite() bool {
+	return t.Kind == KindObject || t.Kind == KindInterface || t.Kind == KindUnion
+}
+
+// possibleTypes returns the object types a value of the type can be.
+func (t *TypeDef) possibleTypes() []string {
+	if t.Kind == KindObject {
+		return []string{t.Name}
+	}
+	return t.PossibleTypes
+}
+
+// MustParseType parses a type reference as it's written in GraphQL, e.g. [String!]!.
+// It panics if the type is invalid, since types are given by generated code.
+func MustParseType(s string) *Type {
+	p := &parser{lexer: lexer{src: s, line: 1}}
+	defer func() {
+		if r := recover(); r != nil {
+			panic(fmt.Sprintf("exec: invalid type %q: %v", s, r))
+		}
+	}()
+
+	p.next()
+	t := p.typ()
+	p.expect(tokEOF)
+	return t
+}
+
+// named returns the named type of a type reference, i.e. without its lists and non-null.
+func (t *Type) named() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
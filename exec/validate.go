@@ -0,0 +1,536 @@
+package exec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Errors are the errors of a request, which failed validation.
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// validator validates a query document against a schema, as per the validation
+// section of the spec. Of the values of arguments, only enum values are checked,
+// while anything else is checked by the generated decoders when a field is executed.
+type validator struct {
+	schema *Schema
+	doc    *Document
+	errs   Errors
+
+	// reported dedupes errors, since fragments are checked for conflicts wherever they're spread
+	reported map[string]bool
+}
+
+// Validate validates a query document against a schema.
+func Validate(s *Schema, doc *Document) error {
+	v := &validator{schema: s, doc: doc, reported: make(map[string]bool)}
+	used := make(map[string]bool)
+	for _, op := range doc.Operations {
+		v.operation(op, used)
+	}
+
+	visited := make(map[string]bool, len(doc.Fragments))
+	for _, name := range v.fragmentNames() {
+		frag := doc.Fragments[name]
+		if !used[name] {
+			v.errorf([]Location{frag.Loc}, "fragment %q is never used", name)
+		}
+		v.fragmentCycles(frag, nil, make(map[string]int), visited)
+		v.directives(frag.Directives)
+
+		t := v.typeCondition(frag.TypeCondition, frag.Loc)
+		if t != nil {
+			v.selectionSet(t, frag.Selections)
+		}
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) errorf(locs []Location, format string, args ...interface{}) {
+	err := &Error{Message: fmt.Sprintf(format, args...), Locations: locs}
+	key := err.Error()
+	if v.reported[key] {
+		return
+	}
+	v.reported[key] = true
+	v.errs = append(v.errs, err)
+}
+
+// fragmentNames returns the names of the fragments in the order they're declared.
+func (v *validator) fragmentNames() []string {
+	names := make([]string, 0, len(v.doc.Fragments))
+	for name := range v.doc.Fragments {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := v.doc.Fragments[names[i]].Loc, v.doc.Fragments[names[j]].Loc
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return names
+}
+
+func (v *validator) operation(op *Operation, used map[string]bool) {
+	root := v.schema.rootType(op.Type)
+	if root == nil {
+		v.errorf([]Location{op.Loc}, "schema does not support %s operations", op.Type)
+		return
+	}
+	if len(v.doc.Operations) > 1 && op.Name == "" {
+		v.errorf([]Location{op.Loc}, "anonymous operations must be the only operation in a document")
+	}
+
+	// Check the variables against their usages
+	defined := make(map[string]bool, len(op.Vars))
+	for _, def := range op.Vars {
+		if defined[def.Name] {
+			v.errorf([]Location{def.Loc}, "there can be only one variable named $%s", def.Name)
+		}
+		defined[def.Name] = true
+
+		t := v.schema.Type(def.Type.named())
+		switch {
+		case t == nil:
+			v.errorf([]Location{def.Loc}, "unknown type %q", def.Type.named())
+		case t.isComposite():
+			v.errorf([]Location{def.Loc}, "variable $%s cannot be of non-input type %s", def.Name, def.Type)
+		case def.HasDefault:
+			v.value(def.Type, def.Default, def.Loc, "variable $"+def.Name)
+		}
+	}
+
+	vars := make(map[string]Location)
+	spread := make(map[string]bool)
+	v.variables(op.Selections, vars, spread)
+	for name := range spread {
+		used[name] = true
+	}
+	for _, d := range op.Directives {
+		usages(d.Args, d.Loc, vars)
+	}
+	for name, loc := range vars {
+		if !defined[name] {
+			v.errorf([]Location{loc, op.Loc}, "variable $%s is not defined by operation %q", name, op.Name)
+		}
+	}
+	for _, def := range op.Vars {
+		if _, ok := vars[def.Name]; !ok {
+			v.errorf([]Location{def.Loc}, "variable $%s is never used in operation %q", def.Name, op.Name)
+		}
+	}
+
+	v.directives(op.Directives)
+	v.selectionSet(root, op.Selections)
+	v.conflicts([]selectionSet{{parent: root, sels: op.Selections}})
+}
+
+// variables collects the variables used by a selection set, along with the fragments it spreads.
+func (v *validator) variables(sels []Selection, vars map[string]Location, spread map[string]bool) {
+	for _, sel := range sels {
+		switch s := sel.(type) {
+		case *Field:
+			usages(s.Args, s.Loc, vars)
+			for _, d := range s.Directives {
+				usages(d.Args, d.Loc, vars)
+			}
+			v.variables(s.Selections, vars, spread)
+		case *InlineFragment:
+			for _, d := range s.Directives {
+				usages(d.Args, d.Loc, vars)
+			}
+			v.variables(s.Selections, vars, spread)
+		case *FragmentSpread:
+			for _, d := range s.Directives {
+				usages(d.Args, d.Loc, vars)
+			}
+			frag, ok := v.doc.Fragments[s.Name]
+			if !ok || spread[s.Name] {
+				continue
+			}
+			spread[s.Name] = true
+			for _, d := range frag.Directives {
+				usages(d.Args, d.Loc, vars)
+			}
+			v.variables(frag.Selections, vars, spread)
+		}
+	}
+}
+
+// usages collects the variables used by a value.
+func usages(val interface{}, loc Location, vars map[string]Location) {
+	switch w := val.(type) {
+	case Variable:
+		if _, ok := vars[string(w)]; !ok {
+			vars[string(w)] = loc
+		}
+	case []interface{}:
+		for _, e := range w {
+			usages(e, loc, vars)
+		}
+	case map[string]interface{}:
+		for _, e := range w {
+			usages(e, loc, vars)
+		}
+	}
+}
+
+// fragmentCycles reports fragments which spread themselves, directly or through other
+// fragments. The path holds the spreads which led to the fragment, and index the positions
+// in the path at which the fragments on it were entered, so every cycle is reported once.
+func (v *validator) fragmentCycles(frag *Fragment, path []*FragmentSpread, index map[string]int, visited map[string]bool) {
+	if visited[frag.Name] {
+		return
+	}
+	visited[frag.Name] = true
+
+	index[frag.Name] = len(path)
+	defer delete(index, frag.Name)
+
+	for _, s := range spreads(frag.Selections, nil) {
+		next, ok := v.doc.Fragments[s.Name]
+		if !ok {
+			continue
+		}
+
+		i, ok := index[s.Name]
+		if !ok {
+			v.fragmentCycles(next, append(path, s), index, visited)
+			continue
+		}
+
+		cycle := append(path[i:len(path):len(path)], s)
+		locs := make([]Location, len(cycle))
+		via := make([]string, len(cycle)-1)
+		for j, p := range cycle {
+			locs[j] = p.Loc
+			if j < len(via) {
+				via[j] = fmt.Sprintf("%q", p.Name)
+			}
+		}
+
+		msg := fmt.Sprintf("cannot spread fragment %q within itself", s.Name)
+		if len(via) > 0 {
+			msg += " via " + strings.Join(via, ", ")
+		}
+		v.errorf(locs, "%s", msg)
+	}
+}
+
+// spreads returns the fragment spreads of a selection set, including those of nested selections.
+func spreads(sels []Selection, acc []*FragmentSpread) []*FragmentSpread {
+	for _, sel := range sels {
+		switch s := sel.(type) {
+		case *Field:
+			acc = spreads(s.Selections, acc)
+		case *InlineFragment:
+			acc = spreads(s.Selections, acc)
+		case *FragmentSpread:
+			acc = append(acc, s)
+		}
+	}
+	return acc
+}
+
+// typeCondition returns the type of a fragments' type condition, if it's a composite type.
+func (v *validator) typeCondition(name string, loc Location) *TypeDef {
+	t := v.schema.Type(name)
+	switch {
+	case t == nil:
+		v.errorf([]Location{loc}, "unknown type %q", name)
+		return nil
+	case !t.isComposite():
+		v.errorf([]Location{loc}, "fragment cannot condition on non composite type %q", name)
+		return nil
+	}
+	return t
+}
+
+// selectionSet validates the selections on a value of the given type.
+func (v *validator) selectionSet(parent *TypeDef, sels []Selection) {
+	for _, sel := range sels {
+		switch s := sel.(type) {
+		case *Field:
+			v.directives(s.Directives)
+			v.field(parent, s)
+		case *InlineFragment:
+			v.directives(s.Directives)
+			t := parent
+			if s.TypeCondition != "" {
+				if t = v.typeCondition(s.TypeCondition, s.Loc); t == nil {
+					continue
+				}
+			}
+			v.spreadable(parent, t, "fragment", s.Loc)
+			v.selectionSet(t, s.Selections)
+		case *FragmentSpread:
+			v.directives(s.Directives)
+			frag, ok := v.doc.Fragments[s.Name]
+			if !ok {
+				v.errorf([]Location{s.Loc}, "unknown fragment %q", s.Name)
+				continue
+			}
+			if t := v.schema.Type(frag.TypeCondition); t != nil && t.isComposite() {
+				v.spreadable(parent, t, fmt.Sprintf("fragment %q", s.Name), s.Loc)
+			}
+		}
+	}
+}
+
+// spreadable reports fragments, which can never apply to a value of the parent type.
+func (v *validator) spreadable(parent, t *TypeDef, what string, loc Location) {
+	for _, a := range parent.possibleTypes() {
+		for _, b := range t.possibleTypes() {
+			if a == b {
+				return
+			}
+		}
+	}
+	v.errorf([]Location{loc}, "%s cannot be spread here as objects of type %q can never be of type %q", what, parent.Name, t.Name)
+}
+
+func (v *validator) field(parent *TypeDef, f *Field) {
+	def := v.fieldDef(parent, f.Name)
+	if def == nil {
+		v.errorf([]Location{f.Loc}, "cannot query field %q on type %q", f.Name, parent.Name)
+		return
+	}
+	v.args(def.Args, f.Args, f.Loc, fmt.Sprintf("field %q", parent.Name+"."+f.Name))
+
+	t := v.schema.Type(def.Type.named())
+	if t == nil {
+		return
+	}
+	switch {
+	case t.isComposite() && f.Selections == nil:
+		v.errorf([]Location{f.Loc}, "field %q of type %q must have a selection of subfields", f.Name, def.Type.String())
+	case !t.isComposite() && f.Selections != nil:
+		v.errorf([]Location{f.Loc}, "field %q must not have a selection since type %q has no subfields", f.Name, def.Type.String())
+	case t.isComposite():
+		v.selectionSet(t, f.Selections)
+	}
+}
+
+// fieldDef returns the definition of a field selected on the given type, including meta fields.
+func (v *validator) fieldDef(parent *TypeDef, name string) *FieldDef {
+	if def := v.schema.metaField(parent, name); def != nil {
+		return def
+	}
+	return parent.Field(name)
+}
+
+// args checks the given arguments against their definitions. Required arguments,
+// i.e. non-null ones without a default, must be given.
+func (v *validator) args(defs []*InputValueDef, args map[string]interface{}, loc Location, of string) {
+	for name := range args {
+		found := false
+		for _, def := range defs {
+			found = found || def.Name == name
+		}
+		if !found {
+			v.errorf([]Location{loc}, "unknown argument %q on %s", name, of)
+		}
+	}
+
+	for _, def := range defs {
+		if !def.Type.NonNull || def.DefaultValue != "" {
+			continue
+		}
+		if val, ok := args[def.Name]; !ok || val == nil {
+			v.errorf([]Location{loc}, "argument %q of type %q is required on %s", def.Name, def.Type.String(), of)
+		}
+	}
+
+	for _, def := range defs {
+		if val, ok := args[def.Name]; ok {
+			v.value(def.Type, val, loc, fmt.Sprintf("argument %q on %s", def.Name, of))
+		}
+	}
+}
+
+// value reports enum values of a literal, which aren't declared by their enum,
+// including those in lists and input objects. Variables are coerced at execution.
+func (v *validator) value(typ *Type, val interface{}, loc Location, of string) {
+	if val == nil {
+		return
+	}
+	if typ.Elem != nil {
+		l, ok := val.([]interface{})
+		if !ok {
+			// A single value is coerced to a list of one
+			v.value(typ.Elem, val, loc, of)
+			return
+		}
+		for _, e := range l {
+			v.value(typ.Elem, e, loc, of)
+		}
+		return
+	}
+
+	t := v.schema.Type(typ.Name)
+	if t == nil {
+		return
+	}
+	switch t.Kind {
+	case KindEnum:
+		if _, ok := val.(Variable); ok {
+			return
+		}
+		if name, ok := val.(string); ok && t.enumValue(name) != nil {
+			return
+		}
+		v.errorf([]Location{loc}, "value %v of %s is not a value of enum %q", val, of, t.Name)
+	case KindInputObject:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, f := range t.InputFields {
+			if fv, ok := m[f.Name]; ok {
+				v.value(f.Type, fv, loc, of)
+			}
+		}
+	}
+}
+
+func (v *validator) directives(dirs []*Directive) {
+	seen := make(map[string]bool, len(dirs))
+	for _, d := range dirs {
+		def := v.schema.Directive(d.Name)
+		if def == nil {
+			v.errorf([]Location{d.Loc}, "unknown directive @%s", d.Name)
+			continue
+		}
+		if seen[d.Name] {
+			v.errorf([]Location{d.Loc}, "the directive @%s can only be used once at this location", d.Name)
+		}
+		seen[d.Name] = true
+		v.args(def.Args, d.Args, d.Loc, "directive @"+d.Name)
+	}
+}
+
+// selectionSet is a selection set, along with the type it's selected on.
+type selectionSet struct {
+	parent *TypeDef
+	sels   []Selection
+}
+
+// collectedField is a field, along with the type it's selected on and its definition.
+type collectedField struct {
+	parent *TypeDef
+	field  *Field
+	def    *FieldDef
+}
+
+// conflicts reports fields with the same response key, which can't be merged since they select
+// different fields, with different arguments, or have types of a different shape. The selection
+// sets are those which are merged, i.e. the selections of fields with the same response key.
+func (v *validator) conflicts(sets []selectionSet) {
+	var keys []string
+	fields := make(map[string][]collectedField)
+	for _, set := range sets {
+		v.collect(set.parent, set.sels, fields, &keys, make(map[string]bool))
+	}
+
+	for _, key := range keys {
+		group := fields[key]
+		for i := 0; i < len(group); i++ {
+			for j := i + 1; j < len(group); j++ {
+				v.conflict(key, group[i], group[j])
+			}
+		}
+
+		// The selections of all fields with the same response key are merged
+		var subs []selectionSet
+		for _, cf := range group {
+			if cf.def == nil || cf.field.Selections == nil {
+				continue
+			}
+			if t := v.schema.Type(cf.def.Type.named()); t != nil && t.isComposite() {
+				subs = append(subs, selectionSet{parent: t, sels: cf.field.Selections})
+			}
+		}
+		if len(subs) > 0 {
+			v.conflicts(subs)
+		}
+	}
+}
+
+// collect collects the fields of a selection set by their response key, following fragments.
+func (v *validator) collect(parent *TypeDef, sels []Selection, fields map[string][]collectedField, keys *[]string, visited map[string]bool) {
+	for _, sel := range sels {
+		switch s := sel.(type) {
+		case *Field:
+			cf := collectedField{parent: parent, field: s, def: v.fieldDef(parent, s.Name)}
+			if _, ok := fields[s.Alias]; !ok {
+				*keys = append(*keys, s.Alias)
+			}
+			fields[s.Alias] = append(fields[s.Alias], cf)
+		case *InlineFragment:
+			t := parent
+			if s.TypeCondition != "" {
+				t = v.schema.Type(s.TypeCondition)
+			}
+			if t != nil {
+				v.collect(t, s.Selections, fields, keys, visited)
+			}
+		case *FragmentSpread:
+			frag, ok := v.doc.Fragments[s.Name]
+			if !ok || visited[s.Name] {
+				continue
+			}
+			visited[s.Name] = true
+			if t := v.schema.Type(frag.TypeCondition); t != nil {
+				v.collect(t, frag.Selections, fields, keys, visited)
+			}
+		}
+	}
+}
+
+// conflict reports whether two fields with the same response key can't be merged. Fields
+// on different object types may select different fields, but their types must still match.
+func (v *validator) conflict(key string, a, b collectedField) {
+	locs := []Location{a.field.Loc, b.field.Loc}
+	if a.parent == b.parent || a.parent.Kind != KindObject || b.parent.Kind != KindObject {
+		if a.field.Name != b.field.Name {
+			v.errorf(locs, "fields %q conflict because %q and %q are different fields", key, a.field.Name, b.field.Name)
+			return
+		}
+		if !reflect.DeepEqual(a.field.Args, b.field.Args) {
+			v.errorf(locs, "fields %q conflict because they have differing arguments", key)
+			return
+		}
+	}
+
+	if a.def != nil && b.def != nil && !v.sameShape(a.def.Type, b.def.Type) {
+		v.errorf(locs, "fields %q conflict because they return conflicting types %q and %q", key, a.def.Type.String(), b.def.Type.String())
+	}
+}
+
+// sameShape reports whether two types have the same lists and non-null types, and
+// the same leaf types. Composite types are compared by the fields they select instead.
+func (v *validator) sameShape(a, b *Type) bool {
+	if a.NonNull != b.NonNull || (a.Elem == nil) != (b.Elem == nil) {
+		return false
+	}
+	if a.Elem != nil {
+		return v.sameShape(a.Elem, b.Elem)
+	}
+
+	ta, tb := v.schema.Type(a.Name), v.schema.Type(b.Name)
+	if ta == nil || tb == nil || ta.isComposite() && tb.isComposite() {
+		return true
+	}
+	return a.Name == b.Name
+}
@@ -0,0 +1,678 @@
+package golang
+
+import (
+	"github.com/gqlc/graphql/ast"
+	goast "go/ast"
+	gotoken "go/token"
+)
+
+const execImport = "github.com/gqlc/golang/exec"
+
+// completions collects the complete funcs an executor needs, which convert
+// the Go values of a type into their results, by the types' names.
+type completions struct {
+	names map[string]bool
+	queue []interface{}
+}
+
+// executorBackend generates models and resolvers, along with an executor which executes
+// queries by calling them directly i.e. without reflection. Queries are parsed, validated
+// and their fields collected by the exec package.
+type executorBackend struct {
+	BaseBackend
+
+	// query is the query type, which also resolves the introspection fields
+	query string
+
+	c *completions
+}
+
+// Header prints the description of the schema, which requests are validated against, along with
+// the Execute and Subscribe funcs. The executor calls the models and resolvers directly.
+func (b *executorBackend) Header(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	g.models, g.resolvers = true, true
+	b.query = rootOpType(schema, "query")
+	b.c = &completions{names: make(map[string]bool)}
+
+	if err := g.printSchema(schema, types, g.opts.Descriptions); err != nil {
+		return err
+	}
+	return g.printExecute(schema)
+}
+
+// Scalar prints the serialization of a custom scalar.
+func (b *executorBackend) Scalar(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	g.addImport(execImport)
+	g.P()
+	return g.printExecScalar(ts.Name.Name, d.Doc)
+}
+
+// printExecScalar prints the exec.Scalar of a custom scalar. Scalars which aren't
+// mapped to a Go type are passed through as is.
+func (g *Generator) printExecScalar(name string, doc *ast.DocGroup) error {
+	b := newBuilder()
+	scalar := b.block(b.name("exec.Scalar"), b.field("Name", b.str(name)))
+	if text := descrText(doc); len(text) > 0 && g.opts.Descriptions {
+		scalar.Elts = append(scalar.Elts, b.field("Description", b.lit(gotoken.STRING, goString(text))))
+	}
+	s, ok := g.scalars[name]
+	if !ok {
+		s = wellKnownScalars["JSON"]
+	}
+	scalar.Elts = append(scalar.Elts, g.scalarFuncs(b, s)...)
+	return g.printDecl(b, b.varDecl(name+"Type", scalar))
+}
+
+// Object prints the execution of an object.
+func (b *executorBackend) Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	g.addImport("context")
+	g.addImport(execImport)
+
+	obj := ts.Type.(*ast.TypeSpec_Object).Object
+	if ts.Name.Name == g.subscription {
+		return g.printExecSubscription(b.c, obj.Fields)
+	}
+	return g.printExecObject(b.c, ts.Name.Name, obj.Fields, ts.Name.Name == b.query)
+}
+
+// Footer prints the complete funcs, which may need further complete funcs, e.g. for list elements.
+func (b *executorBackend) Footer(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	gb := newBuilder()
+	for i := 0; i < len(b.c.queue); i++ {
+		if err := g.printDecls(gb, g.completeFunc(gb, b.c, b.c.queue[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printExecute prints the Execute and Subscribe funcs, along with the executor.
+func (g *Generator) printExecute(schema *ast.TypeDecl) error {
+	g.addImport("context")
+	g.addImport("fmt")
+	g.addImport(execImport)
+
+	b := newBuilder()
+	params := func() []*goast.Field {
+		return []*goast.Field{
+			b.param("ctx", b.name("context.Context")),
+			b.param("query", b.name("string")),
+			b.param("vars", anyMap()),
+		}
+	}
+	request := func() goast.Stmt {
+		return b.define("req, err", b.call("exec.NewRequest", b.name("query"), b.str(""), b.name("vars"), b.name("schema")))
+	}
+	executor := func() goast.Stmt {
+		return b.gap(b.define("e", b.addr(b.inline(b.name("executor"), b.field("Request", b.name("req"))))))
+	}
+
+	var ops []goast.Stmt
+	for _, op := range []string{"query", "mutation"} {
+		if name := rootOpType(schema, op); name != "" {
+			ops = append(ops, b.caseClause([]goast.Expr{b.str(op)},
+				b.define("data, _", b.call("e.exec"+name, b.name("ctx"), b.name("req.Operation.Selections"))),
+				b.ret(b.call("req.Response", b.name("data"))),
+			))
+		}
+	}
+
+	decls := []goast.Decl{
+		b.typeDecl(b.doc("executor executes the operation of a request."), "executor", b.structType(b.member(nil, "", b.typ("*exec.Request")))),
+		b.method(b.doc("Execute executes a query, along with its variables, by calling the resolvers directly.",
+			"Subscriptions are executed by Subscribe instead."), nil, "Execute",
+			b.funcType(params(), b.typ("*exec.Response")),
+			request(),
+			b.ifErr(b.call("exec.ErrorResponse", b.name("err"))),
+			executor(),
+			b.switchStmt(b.name("req.Operation.Type"), ops...),
+			b.ret(b.call("exec.ErrorResponse", b.call("fmt.Errorf", b.str("%s operations can't be executed"), b.name("req.Operation.Type")))),
+		),
+	}
+	if g.subscription != "" {
+		decls = append(decls, b.method(b.doc("Subscribe executes a subscription, along with its variables, by calling the resolvers",
+			"directly. Every event of the subscribed field is resolved into a response, until the",
+			"resolver closes the event channel."), nil, "Subscribe",
+			b.funcType(params(), b.typ("<-chan *exec.Response"), b.name("error")),
+			request(),
+			b.ifErr(b.name("nil"), b.name("err")),
+			b.ifStmt(nil, b.binary(b.name("req.Operation.Type"), gotoken.NEQ, b.str("subscription")),
+				b.ret(b.name("nil"), b.call("fmt.Errorf", b.str("%s operations can't be subscribed to"), b.name("req.Operation.Type"))),
+			),
+			executor(),
+			b.ret(b.call("e.exec"+g.subscription, b.name("ctx"), b.name("req.Operation.Selections"))),
+		))
+	}
+	return g.printDecls(b, decls...)
+}
+
+// printSchema prints the description of the schema, which requests are validated against.
+func (g *Generator) printSchema(schema *ast.TypeDecl, types []*ast.TypeDecl, descr bool) error {
+	b := newBuilder()
+	def := b.block(b.name("exec.Schema"))
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		if name := rootOpType(schema, op); name != "" {
+			def.Elts = append(def.Elts, b.field(goName(op), b.str(name)))
+		}
+	}
+
+	var directives []*ast.TypeDecl
+	defs := b.block(b.typ("[]*exec.TypeDef"))
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok || isCompilerType(d, ts.TypeSpec) {
+			continue
+		}
+
+		switch ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Schema:
+		case *ast.TypeSpec_Directive:
+			directives = append(directives, d)
+		default:
+			defs.Elts = append(defs.Elts, g.typeDef(b, d, ts.TypeSpec, descr))
+		}
+	}
+	def.Elts = append(def.Elts, b.field("Types", defs))
+
+	if len(directives) > 0 {
+		defs := b.block(b.typ("[]*exec.DirectiveDef"))
+		for _, d := range directives {
+			ts := d.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+			dir := ts.Type.(*ast.TypeSpec_Directive).Directive
+
+			locs := make([]string, len(dir.Locs))
+			for i, loc := range dir.Locs {
+				locs[i] = loc.Loc.String()
+			}
+
+			dd := b.block(nil, b.field("Name", b.str(ts.Name.Name)))
+			dd.Elts = append(dd.Elts, defDescr(b, descr, d.Doc)...)
+			dd.Elts = append(dd.Elts, b.field("Locations", strList(b, locs)))
+			dd.Elts = append(dd.Elts, inputValueDefs(b, "Args", dir.Args, descr)...)
+			defs.Elts = append(defs.Elts, dd)
+		}
+		def.Elts = append(def.Elts, b.field("Directives", defs))
+	}
+
+	g.P()
+	doc := b.doc("schema describes the types of the schema, which requests are validated against.")
+	return g.printDecl(b, b.withDoc(doc, b.varDecl("schema", b.call("exec.NewSchema", def))))
+}
+
+// typeDef returns the description of a named type.
+func (g *Generator) typeDef(b *builder, d *ast.TypeDecl, ts *ast.TypeSpec, descr bool) goast.Expr {
+	var kind string
+	switch ts.Type.(type) {
+	case *ast.TypeSpec_Scalar:
+		kind = "Scalar"
+	case *ast.TypeSpec_Object:
+		kind = "Object"
+	case *ast.TypeSpec_Interface:
+		kind = "Interface"
+	case *ast.TypeSpec_Union:
+		kind = "Union"
+	case *ast.TypeSpec_Enum:
+		kind = "Enum"
+	case *ast.TypeSpec_Input:
+		kind = "InputObject"
+	}
+	def := b.block(nil,
+		b.field("Kind", b.name("exec.Kind"+kind)),
+		b.field("Name", b.str(ts.Name.Name)),
+	)
+	def.Elts = append(def.Elts, defDescr(b, descr, d.Doc)...)
+
+	switch v := ts.Type.(type) {
+	case *ast.TypeSpec_Object:
+		if len(v.Object.Interfaces) > 0 {
+			names := make([]string, len(v.Object.Interfaces))
+			for i, inter := range v.Object.Interfaces {
+				names[i] = inter.Name
+			}
+			def.Elts = append(def.Elts, b.field("Interfaces", strList(b, names)))
+		}
+		def.Elts = append(def.Elts, fieldDefs(b, v.Object.Fields, descr))
+	case *ast.TypeSpec_Interface:
+		def.Elts = append(def.Elts,
+			fieldDefs(b, v.Interface.Fields, descr),
+			b.field("PossibleTypes", strList(b, g.implementers[ts.Name.Name])),
+		)
+	case *ast.TypeSpec_Union:
+		def.Elts = append(def.Elts, b.field("PossibleTypes", strList(b, g.implementers[ts.Name.Name])))
+	case *ast.TypeSpec_Enum:
+		values := b.block(b.typ("[]*exec.EnumValueDef"))
+		for _, val := range v.Enum.Values.GetList() {
+			vd := b.block(nil, b.field("Name", b.str(val.Name.Name)))
+			vd.Elts = append(vd.Elts, defDescr(b, descr, val.Doc)...)
+			vd.Elts = append(vd.Elts, defDeprecation(b, val.Directives)...)
+			values.Elts = append(values.Elts, vd)
+		}
+		def.Elts = append(def.Elts, b.field("EnumValues", values))
+	case *ast.TypeSpec_Input:
+		def.Elts = append(def.Elts, inputValueDefs(b, "InputFields", v.Input.Fields, descr)...)
+	}
+	return def
+}
+
+// fieldDefs returns the descriptions of the fields of an object or interface.
+func fieldDefs(b *builder, fields *ast.FieldList, descr bool) goast.Expr {
+	defs := b.block(b.typ("[]*exec.FieldDef"))
+	for _, f := range fields.GetList() {
+		fd := b.block(nil, b.field("Name", b.str(f.Name.Name)))
+		fd.Elts = append(fd.Elts, defDescr(b, descr, f.Doc)...)
+		fd.Elts = append(fd.Elts, inputValueDefs(b, "Args", f.Args, descr)...)
+		fd.Elts = append(fd.Elts, b.field("Type", b.call("exec.MustParseType", b.str(typeString(fieldType(f))))))
+		fd.Elts = append(fd.Elts, defDeprecation(b, f.Directives)...)
+		defs.Elts = append(defs.Elts, fd)
+	}
+	return b.field("Fields", defs)
+}
+
+// inputValueDefs returns the descriptions of arguments or input fields, if there are any.
+func inputValueDefs(b *builder, name string, args *ast.InputValueList, descr bool) []goast.Expr {
+	if args.NumValues() == 0 {
+		return nil
+	}
+
+	defs := b.block(b.typ("[]*exec.InputValueDef"))
+	for _, a := range args.List {
+		ad := b.block(nil, b.field("Name", b.str(a.Name.Name)))
+		ad.Elts = append(ad.Elts, defDescr(b, descr, a.Doc)...)
+		ad.Elts = append(ad.Elts, b.field("Type", b.call("exec.MustParseType", b.str(typeString(inputType(a))))))
+
+		p := &sdlPrinter{}
+		switch v := a.Default.(type) {
+		case *ast.InputValue_BasicLit:
+			p.value(v.BasicLit)
+		case *ast.InputValue_CompositeLit:
+			p.value(v.CompositeLit)
+		}
+		if p.Len() > 0 {
+			ad.Elts = append(ad.Elts, b.field("DefaultValue", b.lit(gotoken.STRING, goString(p.String()))))
+		}
+		defs.Elts = append(defs.Elts, ad)
+	}
+	return []goast.Expr{b.field(name, defs)}
+}
+
+// defDescr returns the Description of a definition, if there's any text.
+func defDescr(b *builder, descr bool, doc *ast.DocGroup) []goast.Expr {
+	if text := descrText(doc); len(text) > 0 && descr {
+		return []goast.Expr{b.field("Description", b.lit(gotoken.STRING, goString(text)))}
+	}
+	return nil
+}
+
+// defDeprecation returns the deprecation of a field or enum value, if it's been deprecated.
+func defDeprecation(b *builder, directives []*ast.DirectiveLit) []goast.Expr {
+	reason, ok := deprecationReason(directives)
+	if !ok {
+		return nil
+	}
+
+	text := b.name("exec.DefaultDeprecationReason")
+	if reason != nil {
+		text = b.lit(gotoken.STRING, goString(stringValue(reason.Value)))
+	}
+	return []goast.Expr{b.field("Deprecated", b.name("true")), b.field("DeprecationReason", text)}
+}
+
+// typeString returns a type reference as it's written in GraphQL.
+func typeString(typ interface{}) string {
+	b := &sdlPrinter{}
+	b.typeRef(typ)
+	return b.String()
+}
+
+// strList returns the given strings as a Go string slice literal.
+func strList(b *builder, l []string) goast.Expr {
+	elts := make([]goast.Expr, len(l))
+	for i, s := range l {
+		elts[i] = b.str(s)
+	}
+	return b.inline(b.typ("[]string"), elts...)
+}
+
+// printExecObject prints the funcs which execute a selection set on an object and resolve
+// its fields. Fields of root operation types aren't resolved from a model, while the query
+// type also resolves the introspection fields.
+func (g *Generator) printExecObject(c *completions, name string, fields *ast.FieldList, query bool) error {
+	b := newBuilder()
+	recv := func() *goast.Field { return b.param("e", b.typ("*executor")) }
+	params := func(params ...*goast.Field) []*goast.Field {
+		params = append([]*goast.Field{b.param("ctx", b.name("context.Context"))}, params...)
+		if !g.roots[name] {
+			params = append(params, b.param("obj", b.typ("*"+name)))
+		}
+		return params
+	}
+
+	clauses := []goast.Stmt{
+		b.caseClause([]goast.Expr{b.str("__typename")}, b.exprStmt(b.call("res.Set", b.name("f.Alias"), b.str(name)))),
+	}
+	if query {
+		clauses = append(clauses, b.caseClause([]goast.Expr{b.str("__schema"), b.str("__type")},
+			b.exprStmt(b.call("res.Set", b.name("f.Alias"), b.call("e.Introspect", b.name("f")))),
+		))
+	}
+	for _, f := range fields.List {
+		args := []goast.Expr{b.name("ctx"), b.name("f")}
+		if !g.roots[name] {
+			args = append(args, b.name("obj"))
+		}
+
+		exec := b.call("e.exec"+name+goName(f.Name.Name), args...)
+		body := []goast.Stmt{b.define("v, _", exec)}
+		if _, ok := fieldType(f).(*ast.NonNull); ok {
+			body = []goast.Stmt{
+				b.define("v, ok", exec),
+				b.ifStmt(nil, b.not(b.name("ok")), b.ret(b.name("nil"), b.name("false"))),
+			}
+		}
+		body = append(body, b.exprStmt(b.call("res.Set", b.name("f.Alias"), b.name("v"))))
+		clauses = append(clauses, b.caseClause([]goast.Expr{b.str(f.Name.Name)}, body...))
+	}
+	clauses = append(clauses, b.caseClause(nil,
+		b.exprStmt(b.call("e.Errorf", b.name("f"), b.str("cannot query field %q on type "+name), b.name("f.Name"))),
+	))
+
+	decls := []goast.Decl{
+		b.method(b.doc("exec"+name+" executes a selection set on a "+name+"."), recv(), "exec"+name,
+			b.funcType(params(b.param("sels", b.typ("[]exec.Selection"))), anyType(), b.name("bool")),
+			b.define("fields", b.call("e.CollectFields", b.str(name), b.name("sels"))),
+			b.define("res", b.call("exec.NewObject", b.call("len", b.name("fields")))),
+			b.rangeStmt("_", "f", b.name("fields"), b.switchStmt(b.name("f.Name"), clauses...)),
+			b.ret(b.name("res"), b.name("true")),
+		),
+	}
+
+	for _, f := range fields.List {
+		typ := fieldType(f)
+		field := name + goName(f.Name.Name)
+
+		var missing []goast.Stmt
+		if g.roots[name] {
+			missing = []goast.Stmt{
+				b.exprStmt(b.call("e.Errorf", b.name("f"), b.str(name+"Resolvers is nil"))),
+				b.ret(b.name("nil"), b.name("false")),
+			}
+		} else {
+			missing = []goast.Stmt{b.ret(b.call("e."+c.complete(typ), b.name("ctx"), b.name("f"), b.name("obj."+goName(f.Name.Name))))}
+		}
+
+		body := []goast.Stmt{b.ifStmt(nil, b.binary(b.name(name+"Resolvers"), gotoken.EQL, b.name("nil")), missing...)}
+		body = append(body, g.execResolve(b, name, f, "v")...)
+		body = append(body, b.ret(b.call("e."+c.complete(typ), b.name("ctx"), b.name("f"), b.name("v"))))
+		decls = append(decls, b.method(b.doc("exec"+field+" resolves "+name+"."+f.Name.Name+"."), recv(), "exec"+field,
+			b.funcType(params(b.param("f", b.typ("*exec.Field"))), anyType(), b.name("bool")),
+			body...,
+		))
+	}
+	return g.printDecls(b, decls...)
+}
+
+// printExecSubscription prints the func which subscribes to the field selected by a
+// subscription, along with the funcs which resolve the events of every field.
+func (g *Generator) printExecSubscription(c *completions, fields *ast.FieldList) error {
+	name := g.subscription
+	g.addImport("fmt")
+
+	b := newBuilder()
+	recv := func() *goast.Field { return b.param("e", b.typ("*executor")) }
+	results := func() []goast.Expr { return []goast.Expr{b.typ("<-chan *exec.Response"), b.name("error")} }
+	done := func() goast.Stmt {
+		return b.commClause(b.exprStmt(b.recv(b.call("ctx.Done"))), b.ret())
+	}
+
+	var clauses []goast.Stmt
+	for _, f := range fields.List {
+		clauses = append(clauses, b.caseClause([]goast.Expr{b.str(f.Name.Name)},
+			b.ret(b.call("e.exec"+name+goName(f.Name.Name), b.name("ctx"), b.name("f"))),
+		))
+	}
+	params := []*goast.Field{b.param("ctx", b.name("context.Context")), b.param("sels", b.typ("[]exec.Selection"))}
+	decls := []goast.Decl{
+		b.method(b.doc("exec"+name+" subscribes to the field selected on a "+name+"."), recv(), "exec"+name,
+			b.funcType(params, results()...),
+			b.define("fields", b.call("e.CollectFields", b.str(name), b.name("sels"))),
+			b.ifStmt(nil, b.binary(b.call("len", b.name("fields")), gotoken.NEQ, b.lit(gotoken.INT, "1")),
+				b.ret(b.name("nil"), b.call("fmt.Errorf", b.str("subscriptions must select exactly one field"))),
+			),
+			b.gap(b.define("f", b.index(b.name("fields"), b.lit(gotoken.INT, "0")))),
+			b.switchStmt(b.name("f.Name"), clauses...),
+			b.ret(b.name("nil"), b.call("fmt.Errorf", b.str("cannot query field %q on type "+name), b.name("f.Name"))),
+		),
+	}
+
+	for _, f := range fields.List {
+		typ := fieldType(f)
+		field := name + goName(f.Name.Name)
+
+		// Null events of non-null fields are null responses
+		complete := b.call("e."+c.complete(typ), b.name("ctx"), b.name("f"), b.name("ev"))
+		result := []goast.Stmt{
+			b.define("res", b.call("exec.NewObject", b.lit(gotoken.INT, "1"))),
+			b.exprStmt(b.call("res.Set", b.name("f.Alias"), b.name("v"))),
+			b.assign(b.name("data"), b.name("res")),
+		}
+		event := []goast.Stmt{
+			b.ifStmt(nil, b.not(b.name("ok")), b.ret()),
+			b.gap(b.varStmt("data", anyType())),
+		}
+		if _, nonNull := typ.(*ast.NonNull); nonNull {
+			event = append(event, b.ifStmt(b.define("v, ok", complete), b.name("ok"), result...))
+		} else {
+			event = append(event, b.define("v, _", complete))
+			event = append(event, result...)
+		}
+		event = append(event, b.gap(b.selectStmt(
+			b.commClause(&goast.SendStmt{Chan: b.name("c"), Value: b.call("e.Response", b.name("data"))}),
+			done(),
+		)))
+
+		body := []goast.Stmt{
+			b.ifStmt(nil, b.binary(b.name(name+"Resolvers"), gotoken.EQL, b.name("nil")),
+				b.ret(b.name("nil"), b.call("fmt.Errorf", b.str(name+"Resolvers is nil"))),
+			),
+		}
+		body = append(body, g.execResolve(b, name, f, "events")...)
+		body = append(body,
+			b.gap(b.define("c", b.call("make", &goast.ChanType{Dir: goast.SEND | goast.RECV, Value: b.typ("*exec.Response")}))),
+			&goast.GoStmt{Call: &goast.CallExpr{Fun: b.funcLit(b.funcType(nil),
+				&goast.DeferStmt{Call: b.call("close", b.name("c"))},
+				b.forStmt(b.selectStmt(b.commClause(b.define("ev, ok", b.recv(b.name("events"))), event...), done())),
+			)}},
+			b.ret(b.name("c"), b.name("nil")),
+		)
+
+		params := []*goast.Field{b.param("ctx", b.name("context.Context")), b.param("f", b.typ("*exec.Field"))}
+		decls = append(decls, b.method(b.doc("exec"+field+" subscribes to "+name+"."+f.Name.Name+"."), recv(), "exec"+field,
+			b.funcType(params, results()...),
+			body...,
+		))
+	}
+	return g.printDecls(b, decls...)
+}
+
+// execResolve returns the call of a fields' resolver, which assigns its result to the given
+// variable. Any errors are recorded, except those of subscriptions, which are returned instead.
+func (g *Generator) execResolve(b *builder, typ string, f *ast.Field, res string) []goast.Stmt {
+	check := func() goast.Stmt {
+		if typ == g.subscription {
+			return b.ifErr(b.name("nil"), b.name("err"))
+		}
+		return b.ifStmt(nil, b.binary(b.name("err"), gotoken.NEQ, b.name("nil")),
+			b.exprStmt(b.call("e.Error", b.name("f"), b.name("err"))),
+			b.ret(b.name("nil"), b.name("false")),
+		)
+	}
+
+	var stmts []goast.Stmt
+	params := []goast.Expr{b.name("ctx")}
+	if !g.roots[typ] {
+		params = append(params, b.name("obj"))
+	}
+	if f.Args.NumValues() > 0 {
+		stmts = append(stmts,
+			b.define("args, err", b.call("decode"+argsName(typ, f), b.name("f.Args"))),
+			check(),
+		)
+		params = append(params, b.name("args"))
+	}
+	return append(stmts,
+		b.define(res+", err", b.call(typ+"Resolvers."+goName(f.Name.Name), params...)),
+		check(),
+	)
+}
+
+// complete returns the name of the complete func of the given type, which is queued
+// for generation if it's new. Complete funcs convert the Go value of a type into its
+// result, which is nil and not ok for null values.
+func (c *completions) complete(typ interface{}) string {
+	name := "complete" + typeRefName(typ)
+	if !c.names[name] {
+		c.names[name] = true
+		c.queue = append(c.queue, typ)
+	}
+	return name
+}
+
+// typeRefName returns a name for a type reference, e.g. NonNullListOfUser for [User]!.
+func typeRefName(typ interface{}) string {
+	switch v := typ.(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.List:
+		switch w := v.Type.(type) {
+		case *ast.List_Ident:
+			return "ListOf" + typeRefName(w.Ident)
+		case *ast.List_List:
+			return "ListOf" + typeRefName(w.List)
+		case *ast.List_NonNull:
+			return "ListOf" + typeRefName(w.NonNull)
+		}
+	case *ast.NonNull:
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			return "NonNull" + typeRefName(w.Ident)
+		case *ast.NonNull_List:
+			return "NonNull" + typeRefName(w.List)
+		}
+	}
+	return ""
+}
+
+// completeFunc returns the complete func of the given type. Null values of non-null
+// types are recorded as errors, unless they're due to an error of a nested field.
+func (g *Generator) completeFunc(b *builder, c *completions, typ interface{}) goast.Decl {
+	name := c.complete(typ)
+	params := []*goast.Field{
+		b.param("ctx", b.name("context.Context")),
+		b.param("f", b.typ("*exec.Field")),
+		b.param("v", b.typ(g.goType(typ, false))),
+	}
+	msg := "%s: null value for type " + typeString(typ)
+	nullErr := func() goast.Stmt {
+		return b.exprStmt(b.call("e.Errorf", b.name("f"), b.str(msg), b.name("f.Name")))
+	}
+
+	nonNull := false
+	if v, ok := typ.(*ast.NonNull); ok {
+		nonNull = true
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			typ = w.Ident
+		case *ast.NonNull_List:
+			typ = w.List
+		}
+	}
+	nullCheck := func() goast.Stmt {
+		var body []goast.Stmt
+		if nonNull {
+			body = append(body, nullErr())
+		}
+		body = append(body, b.ret(b.name("nil"), b.name("false")))
+		return b.ifStmt(nil, b.binary(b.name("v"), gotoken.EQL, b.name("nil")), body...)
+	}
+
+	var body []goast.Stmt
+	switch v := typ.(type) {
+	case *ast.Ident:
+		val := b.name("v")
+		if !nonNull {
+			val = b.star(b.name("v"))
+		}
+
+		switch ts := g.typeSpecs[v.Name]; ts.GetType().(type) {
+		case *ast.TypeSpec_Scalar:
+			body = append(body, b.define("r", b.call(v.Name+"Type.Serialize", b.name("v"))))
+			if nonNull {
+				body = append(body, b.ifStmt(nil, b.binary(b.name("r"), gotoken.EQL, b.name("nil")), nullErr()))
+			}
+			body = append(body, b.ret(b.name("r"), b.binary(b.name("r"), gotoken.NEQ, b.name("nil"))))
+		case *ast.TypeSpec_Enum:
+			if !nonNull {
+				body = append(body, nullCheck())
+			}
+			body = append(body, b.ret(b.call("string", val), b.name("true")))
+		case *ast.TypeSpec_Object:
+			body = append(body,
+				nullCheck(),
+				b.ret(b.call("e.exec"+v.Name, b.name("ctx"), b.name("f.Selections"), b.name("v"))),
+			)
+		case *ast.TypeSpec_Interface, *ast.TypeSpec_Union:
+			body = append(body, nullCheck())
+			if impls := g.implementers[v.Name]; len(impls) > 0 {
+				clauses := make([]goast.Stmt, len(impls))
+				for i, obj := range impls {
+					clauses[i] = b.caseClause([]goast.Expr{b.typ("*" + obj)},
+						b.ret(b.call("e."+c.complete(&ast.Ident{Name: obj}), b.name("ctx"), b.name("f"), b.name("v"))),
+					)
+				}
+				body = append(body, b.typeSwitch("v", b.name("v"), clauses...))
+			}
+			body = append(body,
+				b.exprStmt(b.call("e.Errorf", b.name("f"), b.str("%T is not a possible type of "+v.Name), b.name("v"))),
+				b.ret(b.name("nil"), b.name("false")),
+			)
+		default:
+			// Int, Float, String, Boolean and ID
+			if !nonNull {
+				body = append(body, nullCheck())
+			}
+			body = append(body, b.ret(val, b.name("true")))
+		}
+	case *ast.List:
+		var elem interface{}
+		switch w := v.Type.(type) {
+		case *ast.List_Ident:
+			elem = w.Ident
+		case *ast.List_List:
+			elem = w.List
+		case *ast.List_NonNull:
+			elem = w.NonNull
+		}
+
+		// Non-null lists are empty, rather than null, when they're nil
+		if !nonNull {
+			body = append(body, nullCheck())
+		}
+
+		complete := b.call("e."+c.complete(elem), b.name("ctx"), b.name("f"), b.name("x"))
+		loop := []goast.Stmt{b.assignAll([]goast.Expr{b.index(b.name("l"), b.name("i")), b.name("_")}, complete)}
+		if _, ok := elem.(*ast.NonNull); ok {
+			loop = []goast.Stmt{
+				b.define("r, ok", complete),
+				b.ifStmt(nil, b.not(b.name("ok")), b.ret(b.name("nil"), b.name("false"))),
+				b.assign(b.index(b.name("l"), b.name("i")), b.name("r")),
+			}
+		}
+		body = append(body,
+			b.define("l", b.call("make", anyList(), b.call("len", b.name("v")))),
+			b.rangeStmt("i", "x", b.name("v"), loop...),
+			b.ret(b.name("l"), b.name("true")),
+		)
+	}
+
+	return b.method(nil, b.param("e", b.typ("*executor")), name, b.funcType(params, anyType(), b.name("bool")), body...)
+}
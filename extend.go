@@ -0,0 +1,270 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/gqlc/graphql/ast"
+)
+
+// mergeExtensions merges all type extensions in the given document into
+// the types they extend. The document itself is left untouched; instead,
+// the, possibly extended, schema and types are returned.
+func mergeExtensions(doc *ast.Document) (schema *ast.TypeDecl, types []*ast.TypeDecl, err error) {
+	schema, types = doc.Schema, make([]*ast.TypeDecl, 0, len(doc.Types))
+
+	// Index all type declarations
+	decls := make(map[string]int, len(doc.Types))
+	for _, d := range doc.Types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+
+		decls[typeSpecName(ts.TypeSpec)] = len(types)
+		types = append(types, d)
+	}
+
+	// Merge extensions into their respective types
+	extended := make(map[string]bool)
+	for _, d := range doc.Types {
+		ext, ok := d.Spec.(*ast.TypeDecl_TypeExtSpec)
+		if !ok {
+			continue
+		}
+		ets := ext.TypeExtSpec.Type
+
+		name := typeSpecName(ets)
+		i, exists := decls[name]
+		if !exists {
+			return nil, nil, fmt.Errorf("extension of undeclared type: %s", name)
+		}
+
+		// Copy the type before its first extension is applied
+		if !extended[name] {
+			base := types[i]
+			types[i] = &ast.TypeDecl{
+				Doc:    base.Doc,
+				TokPos: base.TokPos,
+				Tok:    base.Tok,
+				Spec:   &ast.TypeDecl_TypeSpec{TypeSpec: copyTypeSpec(base.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec)},
+			}
+			if base == doc.Schema {
+				schema = types[i]
+			}
+			extended[name] = true
+		}
+
+		err = extendTypeSpec(types[i].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec, ets)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// typeSpecName returns the name of the type, with schemas always being named "schema".
+func typeSpecName(ts *ast.TypeSpec) string {
+	if _, ok := ts.Type.(*ast.TypeSpec_Schema); ok || ts.Name == nil {
+		return "schema"
+	}
+	return ts.Name.Name
+}
+
+// copyTypeSpec copies a type, along with any lists an extension can append to.
+func copyTypeSpec(ts *ast.TypeSpec) *ast.TypeSpec {
+	cts := &ast.TypeSpec{
+		Name:       ts.Name,
+		Directives: append([]*ast.DirectiveLit(nil), ts.Directives...),
+	}
+
+	switch v := ts.Type.(type) {
+	case *ast.TypeSpec_Schema:
+		cts.Type = &ast.TypeSpec_Schema{Schema: &ast.SchemaType{
+			Schema:  v.Schema.Schema,
+			RootOps: copyFieldList(v.Schema.RootOps),
+		}}
+	case *ast.TypeSpec_Object:
+		cts.Type = &ast.TypeSpec_Object{Object: &ast.ObjectType{
+			Object:     v.Object.Object,
+			ImplPos:    v.Object.ImplPos,
+			Interfaces: append([]*ast.Ident(nil), v.Object.Interfaces...),
+			Fields:     copyFieldList(v.Object.Fields),
+		}}
+	case *ast.TypeSpec_Interface:
+		cts.Type = &ast.TypeSpec_Interface{Interface: &ast.InterfaceType{
+			Interface: v.Interface.Interface,
+			Fields:    copyFieldList(v.Interface.Fields),
+		}}
+	case *ast.TypeSpec_Union:
+		cts.Type = &ast.TypeSpec_Union{Union: &ast.UnionType{
+			Union:   v.Union.Union,
+			Members: append([]*ast.Ident(nil), v.Union.Members...),
+		}}
+	case *ast.TypeSpec_Enum:
+		cts.Type = &ast.TypeSpec_Enum{Enum: &ast.EnumType{
+			Enum:   v.Enum.Enum,
+			Values: copyFieldList(v.Enum.Values),
+		}}
+	case *ast.TypeSpec_Input:
+		cts.Type = &ast.TypeSpec_Input{Input: &ast.InputType{
+			Input:  v.Input.Input,
+			Fields: copyInputValueList(v.Input.Fields),
+		}}
+	default:
+		cts.Type = ts.Type
+	}
+	return cts
+}
+
+func copyFieldList(l *ast.FieldList) *ast.FieldList {
+	if l == nil {
+		return &ast.FieldList{}
+	}
+
+	return &ast.FieldList{
+		Opening: l.Opening,
+		List:    append([]*ast.Field(nil), l.List...),
+		Closing: l.Closing,
+	}
+}
+
+func copyInputValueList(l *ast.InputValueList) *ast.InputValueList {
+	if l == nil {
+		return &ast.InputValueList{}
+	}
+
+	return &ast.InputValueList{
+		Opening: l.Opening,
+		List:    append([]*ast.InputValue(nil), l.List...),
+		Closing: l.Closing,
+	}
+}
+
+// extendTypeSpec applies an extension to a copied type.
+func extendTypeSpec(ts, ext *ast.TypeSpec) error {
+	ts.Directives = append(ts.Directives, ext.Directives...)
+
+	mismatch := false
+	switch v := ts.Type.(type) {
+	case *ast.TypeSpec_Schema:
+		e, ok := ext.Type.(*ast.TypeSpec_Schema)
+		if !ok {
+			mismatch = true
+			break
+		}
+
+		if e.Schema.RootOps != nil {
+			if err := checkIdents(ts, "root operation", fieldNames(v.Schema.RootOps), fieldNames(e.Schema.RootOps)); err != nil {
+				return err
+			}
+			v.Schema.RootOps.List = append(v.Schema.RootOps.List, e.Schema.RootOps.List...)
+		}
+	case *ast.TypeSpec_Scalar:
+		_, ok := ext.Type.(*ast.TypeSpec_Scalar)
+		mismatch = !ok
+	case *ast.TypeSpec_Object:
+		e, ok := ext.Type.(*ast.TypeSpec_Object)
+		if !ok {
+			mismatch = true
+			break
+		}
+
+		if err := checkIdents(ts, "interface", v.Object.Interfaces, e.Object.Interfaces); err != nil {
+			return err
+		}
+		v.Object.Interfaces = append(v.Object.Interfaces, e.Object.Interfaces...)
+		if e.Object.Fields != nil {
+			if err := checkIdents(ts, "field", fieldNames(v.Object.Fields), fieldNames(e.Object.Fields)); err != nil {
+				return err
+			}
+			v.Object.Fields.List = append(v.Object.Fields.List, e.Object.Fields.List...)
+		}
+	case *ast.TypeSpec_Interface:
+		e, ok := ext.Type.(*ast.TypeSpec_Interface)
+		if !ok {
+			mismatch = true
+			break
+		}
+
+		if e.Interface.Fields != nil {
+			if err := checkIdents(ts, "field", fieldNames(v.Interface.Fields), fieldNames(e.Interface.Fields)); err != nil {
+				return err
+			}
+			v.Interface.Fields.List = append(v.Interface.Fields.List, e.Interface.Fields.List...)
+		}
+	case *ast.TypeSpec_Union:
+		e, ok := ext.Type.(*ast.TypeSpec_Union)
+		if !ok {
+			mismatch = true
+			break
+		}
+
+		if err := checkIdents(ts, "member", v.Union.Members, e.Union.Members); err != nil {
+			return err
+		}
+		v.Union.Members = append(v.Union.Members, e.Union.Members...)
+	case *ast.TypeSpec_Enum:
+		e, ok := ext.Type.(*ast.TypeSpec_Enum)
+		if !ok {
+			mismatch = true
+			break
+		}
+
+		if e.Enum.Values != nil {
+			if err := checkIdents(ts, "value", fieldNames(v.Enum.Values), fieldNames(e.Enum.Values)); err != nil {
+				return err
+			}
+			v.Enum.Values.List = append(v.Enum.Values.List, e.Enum.Values.List...)
+		}
+	case *ast.TypeSpec_Input:
+		e, ok := ext.Type.(*ast.TypeSpec_Input)
+		if !ok {
+			mismatch = true
+			break
+		}
+
+		if e.Input.Fields != nil {
+			if err := checkIdents(ts, "field", inputNames(v.Input.Fields), inputNames(e.Input.Fields)); err != nil {
+				return err
+			}
+			v.Input.Fields.List = append(v.Input.Fields.List, e.Input.Fields.List...)
+		}
+	default:
+		return fmt.Errorf("type can not be extended: %s", typeSpecName(ts))
+	}
+
+	if mismatch {
+		return fmt.Errorf("extension kind does not match extended type: %s", typeSpecName(ts))
+	}
+	return nil
+}
+
+func fieldNames(l *ast.FieldList) []*ast.Ident {
+	names := make([]*ast.Ident, len(l.GetList()))
+	for i, f := range l.GetList() {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func inputNames(l *ast.InputValueList) []*ast.Ident {
+	names := make([]*ast.Ident, len(l.GetList()))
+	for i, f := range l.GetList() {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// checkIdents returns an error if an extension declares a name, which is already declared.
+func checkIdents(ts *ast.TypeSpec, what string, names, ext []*ast.Ident) error {
+	seen := make(map[string]bool, len(names)+len(ext))
+	for _, id := range names {
+		seen[id.Name] = true
+	}
+	for _, id := range ext {
+		if seen[id.Name] {
+			return fmt.Errorf("duplicate %s in extension of %s: %s", what, typeSpecName(ts), id.Name)
+		}
+		seen[id.Name] = true
+	}
+	return nil
+}
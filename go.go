@@ -8,9 +8,16 @@ import (
 	"fmt"
 	"github.com/gqlc/compiler"
 	"github.com/gqlc/graphql/ast"
+	"github.com/gqlc/graphql/token"
+	goast "go/ast"
+	"go/format"
+	"go/scanner"
+	gotoken "go/token"
 	"io"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -21,6 +28,46 @@ type Options struct {
 
 	// Copy descriptions to Go
 	Descriptions bool `json:"descriptions"`
+
+	// Generate Go models for objects, interfaces, unions, enums and inputs
+	Models bool `json:"models"`
+
+	// Generate resolver interfaces which Resolve funcs delegate to. Implies Models.
+	Resolvers bool `json:"resolvers"`
+
+	// Only include the types reachable from the schema in its Types
+	ReachableTypes bool `json:"reachableTypes"`
+
+	// Layout of the generated files: single, per-type or per-kind. (default: single)
+	Layout string `json:"layout"`
+
+	// Include the version of the generator in the header of generated files
+	Version bool `json:"version"`
+
+	// Include a hash of the schema in the header of generated files, so stale files can be detected
+	SchemaHash bool `json:"schemaHash"`
+
+	// Backend is the GraphQL library to generate code for: graphql-go, i.e. github.com/graphql-go/graphql,
+	// graph-gophers, i.e. github.com/graph-gophers/graphql-go, gqlgen, i.e. github.com/99designs/gqlgen, or
+	// executor, which generates an executor using the exec package. The latter three always generate
+	// resolvers. Any other backend must be registered with RegisterBackend. (default: graphql-go)
+	Backend string `json:"backend"`
+
+	// Format the generated output with gofmt. Otherwise, declarations which are
+	// printed from syntax trees are written without aligning them. (default: true)
+	Format bool `json:"format"`
+
+	// Map custom scalars to Go types, e.g. Time: "time.Time". Besides the basic Go types,
+	// any type implementing encoding.TextMarshaler and encoding.TextUnmarshaler is supported.
+	Scalars map[string]string `json:"scalars"`
+
+	// WellKnownScalars are the names of the well-known scalars, i.e. DateTime, JSON,
+	// UUID, Int64 and URL, which are implemented when declared. (default: none)
+	WellKnownScalars []string `json:"wellKnownScalars"`
+
+	// Libraries maps types, which are generated by type libraries i.e. packages generated
+	// from documents without a schema, to the import paths of those packages.
+	Libraries map[string]string `json:"libraries"`
 }
 
 // Generator generates Go code for a GraphQL schema.
@@ -29,6 +76,62 @@ type Generator struct {
 	bytes.Buffer
 
 	indent []byte
+
+	// cycles maps every type taking part in a reference cycle to its cycle
+	cycles map[string]int
+
+	// deferred holds the fields which must be added to their types in init
+	deferred []deferredField
+
+	// typeSpecs maps type names to their declarations
+	typeSpecs map[string]*ast.TypeSpec
+
+	// imports contains any packages, other than graphql-go, which must be imported
+	imports map[string]bool
+
+	// roots contains the root operation types of the schema
+	roots map[string]bool
+
+	// subscription is the subscription root operation type, if any
+	subscription string
+
+	// implementers maps interfaces and unions to their object types
+	implementers map[string][]string
+
+	// scalars maps custom scalars to their Go types
+	scalars map[string]goScalar
+
+	// libraries maps the types of type libraries to their import paths
+	libraries map[string]string
+
+	// layout is the layout of the generated files, fileName is the name of the
+	// file containing the schema and file the name of the current file
+	layout   string
+	fileName string
+	file     string
+	files    []*outputFile
+
+	// backend is the GraphQL library code is generated for
+	backend string
+
+	// opts are the options of the document being generated
+	opts *Options
+
+	// jsonLiteral reports whether a JSON scalar needs parseJSONLiteral
+	jsonLiteral bool
+
+	models    bool
+	resolvers bool
+}
+
+// deferredField is a field which closes a reference cycle between types
+// and therefore can't be declared within its types' var declaration.
+type deferredField struct {
+	typ     string
+	field   *ast.Field
+	input   *ast.InputValue
+	descr   bool
+	resolve bool
 }
 
 // Reset overrides the bytes.Buffer Reset method to assist in cleaning up some Generator state.
@@ -38,9 +141,65 @@ func (g *Generator) Reset() {
 		g.indent = make([]byte, 0, 5)
 	}
 	g.indent = g.indent[0:0]
+	g.cycles = nil
+	g.deferred = g.deferred[:0]
+	g.typeSpecs = nil
+	g.imports = nil
+	g.roots = nil
+	g.subscription = ""
+	g.implementers = nil
+	g.scalars = nil
+	g.libraries = nil
+	g.jsonLiteral = false
+	g.layout = ""
+	g.fileName = ""
+	g.file = ""
+	g.files = nil
+	g.backend = ""
+	g.opts = nil
+	g.models = false
+	g.resolvers = false
+}
+
+var (
+	typeSuffix      = []byte("Type")
+	directiveSuffix = []byte("Directive")
+)
+
+// builtinTypes contains the names of the types which graphql-go already provides.
+var builtinTypes = map[string]bool{
+	"Int":     true,
+	"Float":   true,
+	"String":  true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+// builtinDirectives contains the names of the directives which graphql-go already provides.
+var builtinDirectives = map[string]bool{
+	"include":    true,
+	"skip":       true,
+	"deprecated": true,
 }
 
-var typeSuffix = []byte("Type")
+// isCompilerType reports whether a type is a builtin type or directive or was
+// registered with the compiler, e.g. the @go directive and its options.
+func isCompilerType(d *ast.TypeDecl, ts *ast.TypeSpec) bool {
+	builtins := builtinTypes
+	if _, ok := ts.Type.(*ast.TypeSpec_Directive); ok {
+		builtins = builtinDirectives
+	}
+	if builtins[typeSpecName(ts)] {
+		return true
+	}
+
+	for _, t := range compiler.Types {
+		if t == d {
+			return true
+		}
+	}
+	return false
+}
 
 // Generate generates Go code for the given document.
 func (g *Generator) Generate(ctx context.Context, doc *ast.Document, opts string) (err error) {
@@ -55,672 +214,516 @@ func (g *Generator) Generate(ctx context.Context, doc *ast.Document, opts string
 		}
 	}()
 	defer g.Unlock()
-	g.Reset()
 
 	// Get generator options
-	gOpts, oerr := getOptions(doc, opts)
-	if oerr != nil {
-		return oerr
+	gOpts, err := getOptions(doc, opts)
+	if err != nil {
+		return
+	}
+
+	return g.generate(ctx, doc, gOpts, doc.Name[:len(doc.Name)-len(filepath.Ext(doc.Name))])
+}
+
+// generate generates Go code for the given document into the Go file with the given name.
+func (g *Generator) generate(ctx context.Context, doc *ast.Document, gOpts *Options, fileName string) (err error) {
+	g.Reset()
+	g.layout, g.fileName, g.file = gOpts.Layout, fileName, fileName
+	g.backend = gOpts.Backend
+	switch g.layout {
+	case layoutSingle, layoutPerType, layoutPerKind:
+	default:
+		return fmt.Errorf("unknown layout: %s", g.layout)
+	}
+	b, err := lookupBackend(g.backend)
+	if err != nil {
+		return
 	}
+	g.opts = gOpts
 
-	// Generate package and imports
-	g.writeHeader(g, []byte(gOpts.Package))
+	// Merge type extensions into the types they extend
+	schema, types, merr := mergeExtensions(doc)
+	if merr != nil {
+		return merr
+	}
 
-	// Generate schema
-	if doc.Schema != nil {
-		g.P("var Schema graphql.Schema")
-		g.P()
+	// Apply the implicit root operation types, if the schema is omitted
+	if schema == nil {
+		schema = implicitSchema(types)
 	}
 
-	// Generate types
-	totalTypes := len(doc.Types) - 1
-	for i, d := range doc.Types {
-		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+	// Index types by name
+	g.typeSpecs = make(map[string]*ast.TypeSpec, len(types))
+	for _, d := range types {
+		ts := d.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+		g.typeSpecs[typeSpecName(ts)] = ts
+	}
+	g.models = gOpts.Models || gOpts.Resolvers
+	g.resolvers = gOpts.Resolvers
+	g.roots = rootTypes(schema)
+	g.subscription = rootOpType(schema, "subscription")
+	g.libraries = gOpts.Libraries
+	g.implementers = findImplementers(types)
+
+	// Map custom scalars to Go types
+	g.scalars = make(map[string]goScalar, len(gOpts.WellKnownScalars)+len(gOpts.Scalars))
+	for _, name := range gOpts.WellKnownScalars {
+		s, ok := wellKnownScalars[name]
 		if !ok {
-			continue
+			return fmt.Errorf("unknown well-known scalar: %s", name)
 		}
-		if _, ok = ts.TypeSpec.Type.(*ast.TypeSpec_Schema); ok {
-			continue
+		g.scalars[name] = s
+	}
+	for name, typ := range gOpts.Scalars {
+		s, serr := parseGoScalar(name, typ)
+		if serr != nil {
+			return serr
 		}
+		g.scalars[name] = s
+	}
 
-		// Generate variable declaration
-		name := ts.TypeSpec.Name.Name
-		g.WriteString("var")
-		g.WriteByte(' ')
-		g.WriteString(name)
-		g.Write(typeSuffix)
-		g.WriteByte(' ')
-		g.WriteByte('=')
-		g.WriteByte(' ')
-		g.WriteString("graphql")
-		g.WriteByte('.')
-
-		// Generate GraphQL*Type construction
-		switch ts.TypeSpec.Type.(type) {
-		case *ast.TypeSpec_Scalar:
-			g.generateScalar(name, gOpts.Descriptions, d.Doc, ts.TypeSpec)
-		case *ast.TypeSpec_Object:
-			g.generateObject(name, gOpts.Descriptions, d.Doc, ts.TypeSpec)
-		case *ast.TypeSpec_Interface:
-			g.generateInterface(name, gOpts.Descriptions, d.Doc, ts.TypeSpec)
-		case *ast.TypeSpec_Union:
-			g.generateUnion(name, gOpts.Descriptions, d.Doc, ts.TypeSpec)
-		case *ast.TypeSpec_Enum:
-			g.generateEnum(name, gOpts.Descriptions, d.Doc, ts.TypeSpec)
-		case *ast.TypeSpec_Input:
-			g.generateInput(name, gOpts.Descriptions, d.Doc, ts.TypeSpec)
-		case *ast.TypeSpec_Directive:
-			g.generateDirective(name, gOpts.Descriptions, d.Doc, ts.TypeSpec)
-		}
-
-		if i != totalTypes {
-			g.P()
-		}
-	}
-
-	if doc.Schema != nil {
-		g.P()
-		g.P("func init() {")
-		g.In()
-
-		g.P("var err error")
-		g.P("Schema, err = graphql.NewSchema(graphql.SchemaConfig{")
-		g.In()
-
-		rootOps := doc.Schema.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Schema).Schema.RootOps.List
-		for _, op := range rootOps {
-			g.Write(g.indent)
-			switch op.Name.Name {
-			case "query":
-				g.WriteString("Query: ")
-			case "mutation":
-				g.WriteString("Mutation: ")
-			case "subscription":
-				g.WriteString("Subscription: ")
-			}
-			g.WriteString(op.Type.(*ast.Field_Ident).Ident.Name)
-			g.Write(typeSuffix)
-			g.WriteByte(',')
-			g.WriteByte('\n')
-		}
+	// Fail early on names, which would make the generated code not compile
+	if err = g.checkGoNames(schema, types); err != nil {
+		return
+	}
 
-		g.Out()
-		g.P("})")
+	// Mark the generated files, along with their source
+	var version, hash string
+	if gOpts.Version {
+		version = generatorVersion()
+	}
+	if gOpts.SchemaHash {
+		hash = schemaHash(printSDL(schema, types, true))
+	}
+	header := fileHeader(doc.Name, version, hash)
 
-		g.P("if err != nil {")
-		g.In()
+	if g.backend != backendGraphQLGo && len(g.libraries) > 0 {
+		return fmt.Errorf("type libraries aren't supported by the %s backend", g.backend)
+	}
 
-		g.P("panic(err)")
+	if err = g.emitTypes(b, schema, types); err != nil {
+		return
+	}
 
-		g.Out()
-		g.P("}")
+	// Generate models
+	if g.models {
+		if err = g.generateModels(types, gOpts.Descriptions); err != nil {
+			return
+		}
+	}
 
-		g.Out()
-		g.P("}")
+	// Generate resolvers
+	if g.resolvers {
+		if err = g.generateResolvers(types, gOpts.Descriptions); err != nil {
+			return
+		}
 	}
 
-	// Extract generator context
-	gCtx := compiler.Context(ctx)
+	// Write generated files
+	return g.writeFiles(compiler.Context(ctx), gOpts, header)
+}
 
-	// Open file to write to
-	goFileName := doc.Name[:len(doc.Name)-len(filepath.Ext(doc.Name))]
-	goFile, err := gCtx.Open(goFileName + ".go")
-	defer goFile.Close()
-	if err != nil {
-		return
+// formatSource formats the generated source with gofmt. If the source
+// can't be parsed, the error includes the offending line of the source.
+func formatSource(src []byte) ([]byte, error) {
+	out, err := format.Source(src)
+	if err == nil {
+		return out, nil
 	}
 
-	// Write generated output
-	_, err = g.WriteTo(goFile)
-	return
+	errs, ok := err.(scanner.ErrorList)
+	if !ok || len(errs) == 0 {
+		return nil, err
+	}
+
+	line := errs[0].Pos.Line
+	lines := bytes.Split(src, []byte{'\n'})
+	if line < 1 || line > len(lines) {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s\n\t%d: %s", errs[0], line, bytes.TrimSpace(lines[line-1]))
 }
 
 var (
 	packagePrefix = []byte("package ")
-	importStmt    = []byte(`import "github.com/graphql-go/graphql"`)
 	newLines      = []byte{'\n', '\n'}
 )
 
-func (g *Generator) writeHeader(w io.Writer, packageName []byte) {
+const graphqlImport = "github.com/graphql-go/graphql"
+
+// writeHeader writes the package clause and imports. The standard library
+// imports are grouped separately from any others, goimports style.
+func writeHeader(w io.Writer, packageName []byte, imports map[string]bool) {
 	w.Write(packagePrefix)
 	w.Write(packageName)
 	w.Write(newLines)
 
-	w.Write(importStmt)
-	w.Write(newLines)
-}
-
-func (g *Generator) generateScalar(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) {
-	g.P("NewScalar(graphql.ScalarConfig{")
-	g.In()
-	g.P("Name: \"", name, "\",")
+	if len(imports) == 1 {
+		for path := range imports {
+			fmt.Fprintf(w, "import %q", path)
+		}
+		w.Write(newLines)
+		return
+	}
+	if len(imports) == 0 {
+		return
+	}
 
-	if doc != nil && descr {
-		text := doc.Text()
-		if len(text) > 0 {
-			g.P("Description: \"", text[:len(text)-1], "\",")
+	var std, other []string
+	for path := range imports {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, path)
+			continue
 		}
+		std = append(std, path)
 	}
+	sort.Strings(std)
+	sort.Strings(other)
 
-	g.P("Serialize: func(value interface{}) interface{} { return nil }, // TODO")
-	g.Out()
+	io.WriteString(w, "import (\n")
+	for _, path := range std {
+		fmt.Fprintf(w, "\t%q\n", path)
+	}
+	if len(std) > 0 {
+		io.WriteString(w, "\n")
+	}
+	for _, path := range other {
+		fmt.Fprintf(w, "\t%q\n", path)
+	}
+	io.WriteString(w, ")\n\n")
+}
 
-	g.P("})")
+// addImport adds a package to the imports of the generated file.
+func (g *Generator) addImport(path string) {
+	if g.imports == nil {
+		g.imports = make(map[string]bool)
+	}
+	g.imports[path] = true
 }
 
-func (g *Generator) generateObject(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) {
-	obj := ts.Type.(*ast.TypeSpec_Object).Object
+func (g *Generator) generateScalar(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) error {
+	b := newBuilder()
+	cfg := b.block(b.name("graphql.ScalarConfig"), b.field("Name", b.str(name)))
 
-	g.P("NewObject(graphql.ObjectConfig{")
-	g.In()
-
-	g.P("Name: \"", name, "\",")
-
-	// Print interfaces
-	interLen := len(obj.Interfaces)
-	if interLen == 1 {
-		g.Write(g.indent)
-		g.WriteString("Interfaces: []*graphql.Interface{ ")
-		g.WriteString(obj.Interfaces[0].Name)
-		g.Write(typeSuffix)
-		g.WriteByte(' ')
-		g.WriteByte('}')
-		g.WriteByte(',')
-		g.WriteByte('\n')
-	}
-	if interLen > 1 {
-		g.Write(g.indent)
-		g.WriteString("Interfaces: []*graphql.Interface{")
-		g.WriteByte('\n')
-		g.In()
+	if text := descrText(doc); len(text) > 0 && descr {
+		cfg.Elts = append(cfg.Elts, b.field("Description", b.lit(gotoken.STRING, goString(text))))
+	}
 
-		for _, inter := range obj.Interfaces {
-			g.P(inter.Name, typeSuffix, ",")
-		}
-
-		g.Out()
-		g.P("},")
-	}
-
-	g.P("Fields: graphql.Fields{")
-	g.In()
-
-	for _, f := range obj.Fields.List {
-		g.P('"', f.Name.Name, '"', ": &graphql.Field{")
-		g.In()
-
-		g.Write(g.indent)
-		g.WriteString("Type: ")
-
-		var fieldType interface{}
-		switch v := f.Type.(type) {
-		case *ast.Field_Ident:
-			fieldType = v.Ident
-		case *ast.Field_List:
-			fieldType = v.List
-		case *ast.Field_NonNull:
-			fieldType = v.NonNull
-		}
-		g.printType(fieldType)
-		g.WriteByte(',')
-		g.WriteByte('\n')
-
-		if f.Args != nil {
-			g.P("Args: graphql.FieldConfigArgument{")
-			g.In()
-
-			for _, a := range f.Args.List {
-				g.P('"', a.Name.Name, '"', ": &graphql.ArgumentConfig{")
-				g.In()
-				g.Write(g.indent)
-				g.WriteString("Type: ")
-
-				var argType interface{}
-				switch v := a.Type.(type) {
-				case *ast.InputValue_Ident:
-					argType = v.Ident
-				case *ast.InputValue_List:
-					argType = v.List
-				case *ast.InputValue_NonNull:
-					argType = v.NonNull
-				}
-				g.printType(argType)
-				g.WriteByte(',')
-				g.WriteByte('\n')
-
-				if a.Default != nil {
-					g.Write(g.indent)
-					g.WriteString("DefaultValue: ")
-
-					var defType interface{}
-					switch v := a.Default.(type) {
-					case *ast.InputValue_BasicLit:
-						defType = v.BasicLit
-					case *ast.InputValue_CompositeLit:
-						defType = v.CompositeLit
-					}
-					g.printVal(defType)
-					g.WriteByte(',')
-					g.WriteByte('\n')
-				}
+	if s, ok := g.scalars[name]; ok {
+		cfg.Elts = append(cfg.Elts, g.scalarFuncs(b, s)...)
+	} else {
+		serialize := b.field("Serialize", b.shortFunc(valueFunc(b), b.ret(b.name("nil"))))
+		cfg.Elts = append(cfg.Elts, b.comment(serialize, "TODO"))
+	}
 
-				if a.Doc != nil && descr {
-					g.printDescr(a.Doc)
-					g.WriteByte('\n')
-				}
+	return g.printDecl(b, b.varDecl(name+"Type", b.call("graphql.NewScalar", cfg)))
+}
 
-				g.Out()
+func (g *Generator) generateObject(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) error {
+	obj := ts.Type.(*ast.TypeSpec_Object).Object
 
-				g.P("},")
-			}
+	b := newBuilder()
+	cfg := b.block(b.name("graphql.ObjectConfig"), b.field("Name", b.str(name)))
 
-			g.Out()
-			g.P("},")
+	// Add interfaces
+	if len(obj.Interfaces) > 0 {
+		inters := b.inline(&goast.ArrayType{Elt: &goast.StarExpr{X: b.name("graphql.Interface")}})
+		if len(obj.Interfaces) > 1 {
+			b.multiline[inters] = true
 		}
-
-		g.P("Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO")
-
-		if f.Doc != nil && descr {
-			g.printDescr(f.Doc)
-			g.WriteByte('\n')
+		for _, inter := range obj.Interfaces {
+			inters.Elts = append(inters.Elts, b.name(g.typeVar(inter.Name)))
 		}
-
-		g.Out()
-		g.P("},")
+		cfg.Elts = append(cfg.Elts, b.field("Interfaces", inters))
 	}
 
-	g.Out()
-
-	g.P("},")
+	cfg.Elts = append(cfg.Elts, b.field("Fields", g.fieldsConfig(b, name, obj.Fields, descr, true)))
 
 	if doc != nil && descr {
-		g.printDescr(doc)
-		g.WriteByte('\n')
+		cfg.Elts = appendDescr(b, cfg.Elts, doc)
 	}
 
-	g.Out()
-	g.P("})")
+	return g.printDecl(b, b.varDecl(name+"Type", b.call("graphql.NewObject", cfg)))
 }
 
-func (g *Generator) generateInterface(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) {
+func (g *Generator) generateInterface(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) error {
 	inter := ts.Type.(*ast.TypeSpec_Interface).Interface
 
-	g.P("NewInterface(graphql.InterfaceConfig{")
-	g.In()
-
-	g.P("Name: \"", name, "\",")
-
-	g.P("Fields: graphql.Fields{")
-	g.In()
-
-	for _, f := range inter.Fields.List {
-		g.P('"', f.Name.Name, "\": &graphql.Field{")
-		g.In()
-
-		g.Write(g.indent)
-		g.WriteString("Type: ")
-
-		var fieldType interface{}
-		switch v := f.Type.(type) {
-		case *ast.Field_Ident:
-			fieldType = v.Ident
-		case *ast.Field_List:
-			fieldType = v.List
-		case *ast.Field_NonNull:
-			fieldType = v.NonNull
-		}
-		g.printType(fieldType)
-		g.WriteByte(',')
-		g.WriteByte('\n')
-
-		if f.Args != nil {
-			g.P("Args: graphql.FieldConfigArgument{")
-			g.In()
-
-			for _, a := range f.Args.List {
-				g.P('"', a.Name.Name, '"', ": &graphql.ArgumentConfig{")
-				g.In()
-				g.Write(g.indent)
-				g.WriteString("Type: ")
-
-				var argType interface{}
-				switch v := a.Type.(type) {
-				case *ast.InputValue_Ident:
-					argType = v.Ident
-				case *ast.InputValue_List:
-					argType = v.List
-				case *ast.InputValue_NonNull:
-					argType = v.NonNull
-				}
-				g.printType(argType)
-
-				if a.Default != nil {
-					g.WriteByte('\n')
-
-					g.Write(g.indent)
-					g.WriteString("DefaultValue: ")
-
-					var defType interface{}
-					switch v := a.Default.(type) {
-					case *ast.InputValue_BasicLit:
-						defType = v.BasicLit
-					case *ast.InputValue_CompositeLit:
-						defType = v.CompositeLit
-					}
-					g.printVal(defType)
-					g.WriteByte(',')
-					g.WriteByte('\n')
-				}
+	b := newBuilder()
+	cfg := b.block(b.name("graphql.InterfaceConfig"), b.field("Name", b.str(name)))
+	cfg.Elts = append(cfg.Elts, b.field("Fields", g.fieldsConfig(b, name, inter.Fields, descr, false)))
 
-				if a.Doc != nil && descr {
-					g.printDescr(a.Doc)
-					g.WriteByte('\n')
-				}
+	if doc != nil && descr {
+		cfg.Elts = appendDescr(b, cfg.Elts, doc)
+	}
 
-				g.Out()
+	return g.printDecl(b, b.varDecl(name+"Type", b.call("graphql.NewInterface", cfg)))
+}
 
-				g.P("},")
-			}
+// fieldsConfig returns the graphql.Fields of an object or interface. Any field
+// which closes a reference cycle is deferred to the init func instead.
+func (g *Generator) fieldsConfig(b *builder, name string, fields *ast.FieldList, descr, resolve bool) goast.Expr {
+	cfg := b.block(b.name("graphql.Fields"))
+	for _, f := range fields.List {
+		if g.isCyclic(name, unwrapType(fieldType(f))) {
+			g.deferred = append(g.deferred, deferredField{typ: name, field: f, descr: descr, resolve: resolve})
+			continue
+		}
 
-			g.Out()
+		fieldCfg := b.block(b.name("graphql.Field"), g.fieldConfig(b, name, f, descr, resolve)...)
+		cfg.Elts = append(cfg.Elts, b.kv(b.str(f.Name.Name), b.addr(fieldCfg)))
+	}
+	return cfg
+}
 
-			g.P("},")
-		}
+// fieldConfig returns the elements of a graphql.Field config.
+func (g *Generator) fieldConfig(b *builder, typ string, f *ast.Field, descr, resolve bool) []goast.Expr {
+	elts := []goast.Expr{b.field("Type", g.typeExpr(b, fieldType(f)))}
 
-		if f.Doc != nil && descr {
-			g.printDescr(f.Doc)
-			g.WriteByte('\n')
-		}
+	if f.Args != nil {
+		elts = append(elts, b.field("Args", g.argsConfig(b, f.Args, descr)))
+	}
 
-		g.Out()
+	switch {
+	case resolve && typ == g.subscription:
+		elts = append(elts, g.subscribeFuncs(b, typ, f)...)
+	case resolve && g.resolvers:
+		elts = append(elts, b.field("Resolve", g.resolverFunc(b, typ, f)))
+	case resolve:
+		todo := b.field("Resolve", b.shortFunc(resolveFunc(b), b.ret(b.name("nil"), b.name("nil"))))
+		elts = append(elts, b.comment(todo, "TODO"))
+	}
 
-		g.P("},")
+	if f.Doc != nil && descr {
+		elts = appendDescr(b, elts, f.Doc)
 	}
 
-	g.Out()
+	return appendDeprecation(b, elts, f.Directives)
+}
 
-	g.P("},")
+// argsConfig returns a graphql.FieldConfigArgument.
+func (g *Generator) argsConfig(b *builder, args *ast.InputValueList, descr bool) goast.Expr {
+	cfg := b.block(b.name("graphql.FieldConfigArgument"))
+	for _, a := range args.List {
+		argCfg := b.block(b.name("graphql.ArgumentConfig"), g.inputValueConfig(b, a, descr)...)
+		cfg.Elts = append(cfg.Elts, b.kv(b.str(a.Name.Name), b.addr(argCfg)))
+	}
+	return cfg
+}
 
-	if doc != nil && descr {
-		g.printDescr(doc)
-		g.WriteByte('\n')
+// inputValueConfig returns the elements of an argument or input field config.
+func (g *Generator) inputValueConfig(b *builder, a *ast.InputValue, descr bool) []goast.Expr {
+	elts := []goast.Expr{b.field("Type", g.typeExpr(b, inputType(a)))}
+
+	if a.Default != nil {
+		var defType interface{}
+		switch v := a.Default.(type) {
+		case *ast.InputValue_BasicLit:
+			defType = v.BasicLit
+		case *ast.InputValue_CompositeLit:
+			defType = v.CompositeLit
+		}
+		elts = append(elts, b.field("DefaultValue", g.valExpr(b, defType, inputType(a))))
 	}
 
-	g.Out()
-	g.P("})")
+	if a.Doc != nil && descr {
+		elts = appendDescr(b, elts, a.Doc)
+	}
+	return elts
 }
 
-func (g *Generator) generateUnion(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) {
+func (g *Generator) generateUnion(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) error {
 	union := ts.Type.(*ast.TypeSpec_Union).Union
 
-	g.P("NewUnion(graphql.UnionConfig{")
-	g.In()
-
-	g.P("Name: \"", name, "\",")
-
-	// Print members
-	memsLen := len(union.Members)
-	if memsLen == 1 {
-		g.P("Types: []*graphql.Object{ ", union.Members[0], typeSuffix, " },")
-	}
-	if memsLen > 1 {
-		g.P("Types: []*graphql.Object{")
-		g.In()
+	b := newBuilder()
+	cfg := b.block(b.name("graphql.UnionConfig"), b.field("Name", b.str(name)))
 
+	// Add members
+	if len(union.Members) > 0 {
+		mems := b.inline(&goast.ArrayType{Elt: &goast.StarExpr{X: b.name("graphql.Object")}})
+		if len(union.Members) > 1 {
+			b.multiline[mems] = true
+		}
 		for _, mem := range union.Members {
-			g.P(mem.Name, typeSuffix, ',')
+			mems.Elts = append(mems.Elts, b.name(g.typeVar(mem.Name)))
 		}
-
-		g.Out()
-		g.P("},")
+		cfg.Elts = append(cfg.Elts, b.field("Types", mems))
 	}
 
-	g.P("ResolveType: func(p graphql.ResolveParams) *graphql.Object { return nil }, // TODO")
-
 	if doc != nil && descr {
-		g.printDescr(doc)
-		g.WriteByte('\n')
+		cfg.Elts = appendDescr(b, cfg.Elts, doc)
 	}
 
-	g.Out()
-	g.P("})")
+	return g.printDecl(b, b.varDecl(name+"Type", b.call("graphql.NewUnion", cfg)))
 }
 
-func (g *Generator) generateEnum(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) {
+func (g *Generator) generateEnum(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) error {
 	enum := ts.Type.(*ast.TypeSpec_Enum).Enum
 
-	g.P("NewEnum(graphql.EnumConfig{")
-	g.In()
-
-	g.P("Name: \"", name, "\",")
+	b := newBuilder()
+	cfg := b.block(b.name("graphql.EnumConfig"), b.field("Name", b.str(name)))
 
 	if doc != nil && descr {
-		g.printDescr(doc)
-		g.WriteByte('\n')
+		cfg.Elts = appendDescr(b, cfg.Elts, doc)
 	}
 
-	g.P("Values: graphql.EnumValueConfigMap{")
-	g.In()
-
+	values := b.block(b.name("graphql.EnumValueConfigMap"))
 	for _, v := range enum.Values.List {
-		g.P('"', v.Name.Name, '"', ": &graphql.EnumValueConfig{")
-		g.In()
-
-		g.P("Value: \"", v.Name.Name, "\",")
+		value := b.str(v.Name.Name)
+		if g.models {
+			value = b.name(enumConst(name, v.Name.Name))
+		}
+		valueCfg := b.block(b.name("graphql.EnumValueConfig"), b.field("Value", value))
 
 		if v.Doc != nil && descr {
-			g.printDescr(v.Doc)
-			g.WriteByte('\n')
+			valueCfg.Elts = appendDescr(b, valueCfg.Elts, v.Doc)
 		}
+		valueCfg.Elts = appendDeprecation(b, valueCfg.Elts, v.Directives)
 
-		g.Out()
-
-		g.P("},")
+		values.Elts = append(values.Elts, b.kv(b.str(v.Name.Name), b.addr(valueCfg)))
 	}
+	cfg.Elts = append(cfg.Elts, b.field("Values", values))
 
-	g.Out()
-	g.P("},")
-
-	g.Out()
-	g.P("})")
+	return g.printDecl(b, b.varDecl(name+"Type", b.call("graphql.NewEnum", cfg)))
 }
 
-func (g *Generator) generateInput(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) {
+func (g *Generator) generateInput(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) error {
 	input := ts.Type.(*ast.TypeSpec_Input).Input
 
-	g.P("NewInputObject(graphql.InputObjectConfig{")
-	g.In()
-
-	g.P("Name: \"", name, "\",")
-
-	g.P("Fields: graphql.InputObjectConfigFieldMap{")
-	g.In()
+	b := newBuilder()
+	cfg := b.block(b.name("graphql.InputObjectConfig"), b.field("Name", b.str(name)))
 
+	fields := b.block(b.name("graphql.InputObjectConfigFieldMap"))
 	for _, f := range input.Fields.List {
-		g.P('"', f.Name.Name, '"', ": &graphql.InputObjectFieldConfig{")
-		g.In()
-		g.Write(g.indent)
-		g.WriteString("Type: ")
-
-		var fieldType interface{}
-		switch v := f.Type.(type) {
-		case *ast.InputValue_Ident:
-			fieldType = v.Ident
-		case *ast.InputValue_List:
-			fieldType = v.List
-		case *ast.InputValue_NonNull:
-			fieldType = v.NonNull
-		}
-		g.printType(fieldType)
-		g.WriteByte(',')
-		g.WriteByte('\n')
-
-		if f.Default != nil {
-			g.Write(g.indent)
-			g.WriteString("DefaultValue: ")
-
-			var defType interface{}
-			switch v := f.Default.(type) {
-			case *ast.InputValue_BasicLit:
-				defType = v.BasicLit
-			case *ast.InputValue_CompositeLit:
-				defType = v.CompositeLit
-			}
-			g.printVal(defType)
-			g.WriteByte(',')
-			g.WriteByte('\n')
-		}
-
-		g.Out()
-
-		if f.Doc != nil && descr {
-			g.printDescr(f.Doc)
-			g.WriteByte('\n')
+		if g.isCyclic(name, unwrapType(inputType(f))) {
+			g.deferred = append(g.deferred, deferredField{typ: name, input: f, descr: descr})
+			continue
 		}
 
-		g.P("},")
+		fieldCfg := b.block(b.name("graphql.InputObjectFieldConfig"), g.inputValueConfig(b, f, descr)...)
+		fields.Elts = append(fields.Elts, b.kv(b.str(f.Name.Name), b.addr(fieldCfg)))
 	}
-
-	g.Out()
-	g.P("},")
+	cfg.Elts = append(cfg.Elts, b.field("Fields", fields))
 
 	if doc != nil && descr {
-		g.printDescr(doc)
-		g.WriteByte('\n')
+		cfg.Elts = appendDescr(b, cfg.Elts, doc)
 	}
 
-	g.Out()
-	g.P("})")
+	return g.printDecl(b, b.varDecl(name+"Type", b.call("graphql.NewInputObject", cfg)))
 }
 
-func (g *Generator) generateDirective(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) {
+func (g *Generator) generateDirective(name string, descr bool, doc *ast.DocGroup, ts *ast.TypeSpec) error {
 	directive := ts.Type.(*ast.TypeSpec_Directive).Directive
 
-	g.P("NewDirective(graphql.DirectiveConfig{")
-	g.In()
-
-	g.P("Name: \"", name, "\",")
+	b := newBuilder()
+	cfg := b.block(b.name("graphql.DirectiveConfig"), b.field("Name", b.str(name)))
 
-	if doc != nil && descr {
-		text := doc.Text()
-		if len(text) > 0 {
-			g.P("Description: \"", text[:len(text)-1], "\",")
-		}
+	if text := descrText(doc); len(text) > 0 && descr {
+		cfg.Elts = append(cfg.Elts, b.field("Description", b.lit(gotoken.STRING, goString(text))))
 	}
 
-	// Print locations
-	locsLen := len(directive.Locs)
-	if locsLen == 1 {
-		g.P("Locations: []string{ ", directive.Locs[0].Loc.String(), " }")
-	}
-	if locsLen > 1 {
-		g.P("Locations: []string{")
-		g.In()
-
+	// Add locations
+	if len(directive.Locs) > 0 {
+		locs := b.inline(&goast.ArrayType{Elt: b.name("string")})
+		if len(directive.Locs) > 1 {
+			b.multiline[locs] = true
+		}
 		for _, loc := range directive.Locs {
-			g.P('"', loc.Loc.String(), "\",")
+			locs.Elts = append(locs.Elts, b.str(loc.Loc.String()))
 		}
-
-		g.Out()
-		g.P("},")
+		cfg.Elts = append(cfg.Elts, b.field("Locations", locs))
 	}
 
 	if directive.Args != nil {
-		g.P("Args: graphql.FieldConfigArgument{")
-		g.In()
-
-		for _, a := range directive.Args.List {
-			g.P('"', a.Name.Name, '"', ": &graphql.ArgumentConfig{")
-			g.In()
-
-			g.Write(g.indent)
-			g.WriteString("Type: ")
-
-			var fieldType interface{}
-			switch v := a.Type.(type) {
-			case *ast.InputValue_Ident:
-				fieldType = v.Ident
-			case *ast.InputValue_List:
-				fieldType = v.List
-			case *ast.InputValue_NonNull:
-				fieldType = v.NonNull
-			}
-			g.printType(fieldType)
-			g.WriteByte(',')
-			g.WriteByte('\n')
-
-			if a.Default != nil {
-				g.Write(g.indent)
-				g.WriteString("DefaultValue: ")
-
-				var defType interface{}
-				switch v := a.Default.(type) {
-				case *ast.InputValue_BasicLit:
-					defType = v.BasicLit
-				case *ast.InputValue_CompositeLit:
-					defType = v.CompositeLit
-				}
-				g.printVal(defType)
-				g.WriteByte(',')
-				g.WriteByte('\n')
-			}
+		cfg.Elts = append(cfg.Elts, b.field("Args", g.argsConfig(b, directive.Args, descr)))
+	}
 
-			if a.Doc != nil && descr {
-				g.printDescr(a.Doc)
-				g.WriteByte('\n')
-			}
+	return g.printDecl(b, b.varDecl(name+"Directive", b.call("graphql.NewDirective", cfg)))
+}
 
-			g.Out()
-			g.P("},")
+// printDeferred prints an init func which adds any deferred fields to their types.
+func (g *Generator) printDeferred() error {
+	b := newBuilder()
+
+	var body []goast.Stmt
+	for _, d := range g.deferred {
+		var name string
+		var cfg goast.Expr
+		if d.field != nil {
+			name = d.field.Name.Name
+			cfg = b.block(b.name("graphql.Field"), g.fieldConfig(b, d.typ, d.field, d.descr, d.resolve)...)
+		} else {
+			name = d.input.Name.Name
+			cfg = b.block(b.name("graphql.InputObjectFieldConfig"), g.inputValueConfig(b, d.input, d.descr)...)
 		}
 
-		g.Out()
+		add := b.call(d.typ+string(typeSuffix)+".AddFieldConfig", b.str(name), b.addr(cfg))
+		body = append(body, &goast.ExprStmt{X: add})
+	}
+
+	return g.printDecl(b, b.funcDecl("init", body...))
+}
 
-		g.P("},")
+// appendDescr appends the Description of a config, if there's any text.
+func appendDescr(b *builder, elts []goast.Expr, doc *ast.DocGroup) []goast.Expr {
+	text := descrText(doc)
+	if len(text) == 0 {
+		return elts
 	}
+	return append(elts, b.field("Description", b.lit(gotoken.STRING, goString(text))))
+}
 
-	g.Out()
-	g.P("})")
+// appendDeprecation appends the DeprecationReason of a field or enum value, if it's been deprecated.
+func appendDeprecation(b *builder, elts []goast.Expr, directives []*ast.DirectiveLit) []goast.Expr {
+	reason, ok := deprecationReason(directives)
+	if !ok {
+		return elts
+	}
+	if reason == nil {
+		return append(elts, b.field("DeprecationReason", b.name("graphql.DefaultDeprecationReason")))
+	}
+	return append(elts, b.field("DeprecationReason", b.lit(gotoken.STRING, goString(stringValue(reason.Value)))))
 }
 
-func (g *Generator) printDescr(doc *ast.DocGroup) {
-	text := doc.Text()
-	if len(text) > 0 {
-		g.Write(g.indent)
-		g.WriteString("Description: \"")
+// deprecationReason returns the reason given by the @deprecated directive, if it's been
+// applied. The reason is nil if it's omitted.
+func deprecationReason(directives []*ast.DirectiveLit) (reason *ast.BasicLit, ok bool) {
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		if d.Args == nil {
+			return nil, true
+		}
 
-		g.WriteString(text[:len(text)-1])
+		for _, arg := range d.Args.Args {
+			if arg.Name.Name != "reason" {
+				continue
+			}
 
-		g.WriteByte('"')
-		g.WriteByte(',')
+			switch v := arg.Value.(type) {
+			case *ast.Arg_BasicLit:
+				reason = v.BasicLit
+			case *ast.Arg_CompositeLit:
+				if bl, ok := v.CompositeLit.Value.(*ast.CompositeLit_BasicLit); ok {
+					reason = bl.BasicLit
+				}
+			}
+		}
+		return reason, true
 	}
+	return nil, false
 }
 
-// printType prints a field type
-func (g *Generator) printType(typ interface{}) {
+// typeExpr returns the graphql-go type of a field type.
+func (g *Generator) typeExpr(b *builder, typ interface{}) goast.Expr {
 	switch v := typ.(type) {
 	case *ast.Ident:
-		name := v.Name
-
-		switch name {
-		case "Int":
-			name = "graphql.Int"
-		case "Float":
-			name = "graphql.Float"
-		case "String":
-			name = "graphql.String"
-		case "Boolean":
-			name = "graphql.Boolean"
-		case "ID":
-			name = "graphql.ID"
-		default:
-			name = name + "Type"
-		}
-
-		g.WriteString(name)
+		switch v.Name {
+		case "Int", "Float", "String", "Boolean", "ID":
+			return b.name("graphql." + v.Name)
+		}
+		return b.name(g.typeVar(v.Name))
 	case *ast.List:
-		g.WriteString("graphql.NewList(")
-
 		switch w := v.Type.(type) {
 		case *ast.List_Ident:
 			typ = w.Ident
@@ -729,82 +732,255 @@ func (g *Generator) printType(typ interface{}) {
 		case *ast.List_NonNull:
 			typ = w.NonNull
 		}
-		g.printType(typ)
+		return b.call("graphql.NewList", g.typeExpr(b, typ))
+	case *ast.NonNull:
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			typ = w.Ident
+		case *ast.NonNull_List:
+			typ = w.List
+		}
+		return b.call("graphql.NewNonNull", g.typeExpr(b, typ))
+	}
+	return nil
+}
+
+// fieldType returns the type of a field.
+func fieldType(f *ast.Field) interface{} {
+	switch v := f.Type.(type) {
+	case *ast.Field_Ident:
+		return v.Ident
+	case *ast.Field_List:
+		return v.List
+	case *ast.Field_NonNull:
+		return v.NonNull
+	}
+	return nil
+}
+
+// inputType returns the type of an argument or input field.
+func inputType(a *ast.InputValue) interface{} {
+	switch v := a.Type.(type) {
+	case *ast.InputValue_Ident:
+		return v.Ident
+	case *ast.InputValue_List:
+		return v.List
+	case *ast.InputValue_NonNull:
+		return v.NonNull
+	}
+	return nil
+}
 
-		g.WriteByte(')')
+// unwrapType returns the named type at the core of any List or NonNull types.
+func unwrapType(typ interface{}) *ast.Ident {
+	switch v := typ.(type) {
+	case *ast.Ident:
+		return v
+	case *ast.List:
+		switch w := v.Type.(type) {
+		case *ast.List_Ident:
+			return w.Ident
+		case *ast.List_List:
+			return unwrapType(w.List)
+		case *ast.List_NonNull:
+			return unwrapType(w.NonNull)
+		}
 	case *ast.NonNull:
-		g.WriteString("graphql.NewNonNull(")
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			return w.Ident
+		case *ast.NonNull_List:
+			return unwrapType(w.List)
+		}
+	}
+	return nil
+}
 
+// valExpr returns a value as the Go value graphql-go would coerce it into, given
+// its GraphQL type: lists become []interface{}, input objects map[string]interface{},
+// enums their internal value and null nil. Values without a type are returned as is.
+func (g *Generator) valExpr(b *builder, val, typ interface{}) goast.Expr {
+	if v, ok := typ.(*ast.NonNull); ok {
 		switch w := v.Type.(type) {
 		case *ast.NonNull_Ident:
 			typ = w.Ident
 		case *ast.NonNull_List:
 			typ = w.List
 		}
-		g.printType(typ)
+	}
+	if v, ok := val.(*ast.CompositeLit); ok {
+		switch w := v.Value.(type) {
+		case *ast.CompositeLit_BasicLit:
+			val = w.BasicLit
+		case *ast.CompositeLit_ListLit:
+			val = w.ListLit
+		case *ast.CompositeLit_ObjLit:
+			val = w.ObjLit
+		}
+	}
 
-		g.WriteByte(')')
+	if v, ok := val.(*ast.BasicLit); ok && v.Kind == token.Token_NULL {
+		return b.name("nil")
+	}
+
+	switch t := typ.(type) {
+	case *ast.List:
+		var elem interface{}
+		switch w := t.Type.(type) {
+		case *ast.List_Ident:
+			elem = w.Ident
+		case *ast.List_List:
+			elem = w.List
+		case *ast.List_NonNull:
+			elem = w.NonNull
+		}
+
+		list := b.inline(anyList())
+		l, ok := val.(*ast.ListLit)
+		if !ok {
+			// Single values are coerced into a list of one
+			list.Elts = append(list.Elts, g.valExpr(b, val, elem))
+			return list
+		}
+
+		for _, v := range listValues(l) {
+			list.Elts = append(list.Elts, g.valExpr(b, v, elem))
+		}
+		return list
+	case *ast.Ident:
+		if x := g.typedValExpr(b, val, t); x != nil {
+			return x
+		}
 	}
-}
 
-// printVal prints a value
-func (g *Generator) printVal(val interface{}) {
 	switch v := val.(type) {
 	case *ast.BasicLit:
-		g.WriteString(v.Value)
+		switch v.Kind {
+		case token.Token_STRING:
+			return b.lit(gotoken.STRING, goString(stringValue(v.Value)))
+		case token.Token_IDENT:
+			return b.str(v.Value)
+		case token.Token_FLOAT:
+			return b.lit(gotoken.FLOAT, v.Value)
+		case token.Token_INT:
+			return b.lit(gotoken.INT, v.Value)
+		}
+		return b.name(v.Value)
 	case *ast.ListLit:
-		g.WriteString("[]interface{}{")
+		list := b.inline(anyList())
+		for _, iv := range listValues(v) {
+			list.Elts = append(list.Elts, g.valExpr(b, iv, nil))
+		}
+		return list
+	case *ast.ObjLit:
+		obj := b.inline(anyMap())
+		for _, p := range v.Fields {
+			obj.Elts = append(obj.Elts, b.kv(b.str(p.Key.Name), g.valExpr(b, p.Val, nil)))
+		}
+		return obj
+	}
+	return nil
+}
 
-		var vals []interface{}
-		switch w := v.List.(type) {
-		case *ast.ListLit_BasicList:
-			for _, bval := range w.BasicList.Values {
-				vals = append(vals, bval)
-			}
-		case *ast.ListLit_CompositeList:
-			for _, cval := range w.CompositeList.Values {
-				vals = append(vals, cval)
-			}
+// typedValExpr returns a value of a named type, if it needs to be coerced
+// into another Go value than the one its literal represents.
+func (g *Generator) typedValExpr(b *builder, val interface{}, typ *ast.Ident) goast.Expr {
+	lit, _ := val.(*ast.BasicLit)
+	switch typ.Name {
+	case "Float":
+		if lit == nil || lit.Kind != token.Token_INT {
+			return nil
+		}
+		return b.lit(gotoken.FLOAT, lit.Value+".0")
+	case "ID":
+		if lit == nil || lit.Kind != token.Token_INT {
+			return nil
+		}
+		return b.str(lit.Value)
+	}
+
+	switch t := g.typeSpecs[typ.Name].GetType().(type) {
+	case *ast.TypeSpec_Enum:
+		if lit == nil || lit.Kind != token.Token_IDENT {
+			return nil
+		}
+
+		if g.models {
+			return b.name(g.qualify(typ.Name, enumConst(typ.Name, lit.Value)))
+		}
+		return b.str(lit.Value)
+	case *ast.TypeSpec_Input:
+		obj, ok := val.(*ast.ObjLit)
+		if !ok {
+			return nil
 		}
 
-		vLen := len(vals) - 1
-		for i, iv := range vals {
-			g.printVal(iv)
-			if i != vLen {
-				g.WriteByte(',')
-				g.WriteByte(' ')
+		// Fields are given in declaration order, with any missing defaults added
+		m := b.inline(anyMap())
+		for _, f := range t.Input.Fields.List {
+			var fv interface{}
+			for _, p := range obj.Fields {
+				if p.Key.Name == f.Name.Name {
+					fv = p.Val
+				}
+			}
+			switch d := f.Default.(type) {
+			case *ast.InputValue_BasicLit:
+				if fv == nil {
+					fv = d.BasicLit
+				}
+			case *ast.InputValue_CompositeLit:
+				if fv == nil {
+					fv = d.CompositeLit
+				}
 			}
+			if fv == nil {
+				continue
+			}
+
+			m.Elts = append(m.Elts, b.kv(b.str(f.Name.Name), g.valExpr(b, fv, inputType(f))))
+		}
+		return m
+	case *ast.TypeSpec_Scalar:
+		s, ok := g.scalars[typ.Name]
+		if !ok || s.kind == "json" {
+			return nil
 		}
 
-		g.WriteByte('}')
-	case *ast.ObjLit:
-		g.WriteByte('{')
-		g.WriteByte(' ')
+		// Mapped scalars parse their defaults, just like any other value
+		return b.call(g.typeVar(typ.Name)+".ParseValue", g.valExpr(b, val, nil))
+	}
+	return nil
+}
 
-		pLen := len(v.Fields) - 1
-		for i, p := range v.Fields {
-			g.WriteString(p.Key.Name)
-			g.WriteString(": ")
+// anyType returns the type interface{}.
+func anyType() goast.Expr {
+	return &goast.InterfaceType{Methods: &goast.FieldList{}}
+}
 
-			g.printVal(p.Val)
+// anyList returns the type []interface{}.
+func anyList() goast.Expr {
+	return &goast.ArrayType{Elt: anyType()}
+}
 
-			if i != pLen {
-				g.WriteByte(',')
-			}
-			g.WriteByte(' ')
-		}
+// anyMap returns the type map[string]interface{}.
+func anyMap() goast.Expr {
+	return &goast.MapType{Key: goast.NewIdent("string"), Value: anyType()}
+}
 
-		g.WriteByte('}')
-	case *ast.CompositeLit:
-		switch w := v.Value.(type) {
-		case *ast.CompositeLit_BasicLit:
-			g.printVal(w.BasicLit)
-		case *ast.CompositeLit_ListLit:
-			g.printVal(w.ListLit)
-		case *ast.CompositeLit_ObjLit:
-			g.printVal(w.ObjLit)
+// listValues returns the values of a list literal.
+func listValues(l *ast.ListLit) (vals []interface{}) {
+	switch w := l.List.(type) {
+	case *ast.ListLit_BasicList:
+		for _, bval := range w.BasicList.Values {
+			vals = append(vals, bval)
+		}
+	case *ast.ListLit_CompositeList:
+		for _, cval := range w.CompositeList.Values {
+			vals = append(vals, cval)
 		}
 	}
+	return
 }
 
 // P prints the arguments to the generated output.
@@ -849,6 +1025,9 @@ func (g *Generator) Out() {
 func getOptions(doc *ast.Document, opts string) (gOpts *Options, err error) {
 	gOpts = &Options{
 		Package: "main",
+		Layout:  layoutSingle,
+		Backend: backendGraphQLGo,
+		Format:  true,
 	}
 
 	// Extract document directive options
@@ -873,6 +1052,93 @@ func getOptions(doc *ast.Document, opts string) (gOpts *Options, err error) {
 				}
 
 				gOpts.Descriptions = b
+			case "models":
+				b, err := strconv.ParseBool(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return gOpts, err
+				}
+
+				gOpts.Models = b
+			case "resolvers":
+				b, err := strconv.ParseBool(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return gOpts, err
+				}
+
+				gOpts.Resolvers = b
+			case "reachableTypes":
+				b, err := strconv.ParseBool(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return gOpts, err
+				}
+
+				gOpts.ReachableTypes = b
+			case "layout":
+				gOpts.Layout, err = strconv.Unquote(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return
+				}
+			case "version":
+				b, err := strconv.ParseBool(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return gOpts, err
+				}
+
+				gOpts.Version = b
+			case "schemaHash":
+				b, err := strconv.ParseBool(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return gOpts, err
+				}
+
+				gOpts.SchemaHash = b
+			case "backend":
+				gOpts.Backend, err = strconv.Unquote(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return
+				}
+			case "format":
+				b, err := strconv.ParseBool(arg.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+				if err != nil {
+					return gOpts, err
+				}
+
+				gOpts.Format = b
+			case "scalars":
+				gOpts.Scalars = make(map[string]string)
+				for _, p := range arg.Val.Value.(*ast.CompositeLit_ObjLit).ObjLit.Fields {
+					typ, err := strconv.Unquote(p.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+					if err != nil {
+						return gOpts, err
+					}
+
+					gOpts.Scalars[p.Key.Name] = typ
+				}
+			case "libraries":
+				gOpts.Libraries = make(map[string]string)
+				for _, p := range arg.Val.Value.(*ast.CompositeLit_ObjLit).ObjLit.Fields {
+					path, err := strconv.Unquote(p.Val.Value.(*ast.CompositeLit_BasicLit).BasicLit.Value)
+					if err != nil {
+						return gOpts, err
+					}
+
+					gOpts.Libraries[p.Key.Name] = path
+				}
+			case "wellKnownScalars":
+				gOpts.WellKnownScalars = make([]string, 0, len(wellKnownScalars))
+				list, ok := arg.Val.Value.(*ast.CompositeLit_ListLit).ListLit.List.(*ast.ListLit_BasicList)
+				if !ok {
+					break
+				}
+
+				for _, v := range list.BasicList.Values {
+					name, err := strconv.Unquote(v.Value)
+					if err != nil {
+						return gOpts, err
+					}
+
+					gOpts.WellKnownScalars = append(gOpts.WellKnownScalars, name)
+				}
 			}
 		}
 	}
@@ -3,16 +3,19 @@ package golang
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"flag"
 	"fmt"
 	"github.com/gqlc/compiler"
 	"github.com/gqlc/graphql/ast"
 	"github.com/gqlc/graphql/parser"
 	"github.com/gqlc/graphql/token"
+	"go/format"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	osexec "os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -93,8 +96,8 @@ func TestScalar(t *testing.T) {
 
 	g.generateScalar("Test", false, nil, ts)
 
-	ex := []byte(`NewScalar(graphql.ScalarConfig{
-	Name: "Test",
+	ex := []byte(`var TestType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:      "Test",
 	Serialize: func(value interface{}) interface{} { return nil }, // TODO
 })
 `)
@@ -102,6 +105,261 @@ func TestScalar(t *testing.T) {
 	compareBytes(t, ex, g.Bytes())
 }
 
+func TestScalarMapping(t *testing.T) {
+	g := &Generator{}
+
+	ts := &ast.TypeSpec{
+		Name: &ast.Ident{Name: "Test"},
+	}
+
+	t.Run("TextMarshaler", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.scalars = map[string]goScalar{"Test": {path: "time", typ: "time.Time"}}
+
+		g.generateScalar("Test", false, nil, ts)
+
+		ex := []byte(`var TestType = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Test",
+	Serialize: func(value interface{}) interface{} {
+		if v, ok := value.(*time.Time); ok && v != nil {
+			value = *v
+		}
+		v, ok := value.(time.Time)
+		if !ok {
+			return nil
+		}
+		b, err := v.MarshalText()
+		if err != nil {
+			return nil
+		}
+		return string(b)
+	},
+	ParseValue: func(value interface{}) interface{} {
+		s, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		var v time.Time
+		if err := v.UnmarshalText([]byte(s)); err != nil {
+			return nil
+		}
+		return v
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		s, ok := valueAST.(*ast.StringValue)
+		if !ok {
+			return nil
+		}
+		var v time.Time
+		if err := v.UnmarshalText([]byte(s.Value)); err != nil {
+			return nil
+		}
+		return v
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+		if !g.imports["time"] || !g.imports[graphqlASTImport] {
+			subT.Fatalf("expected imports of time and %s, but got: %v", graphqlASTImport, g.imports)
+		}
+	})
+
+	t.Run("Int", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.scalars = map[string]goScalar{"Test": {typ: "int64", kind: "int", bits: "64"}}
+
+		g.generateScalar("Test", false, nil, ts)
+
+		ex := []byte(`var TestType = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Test",
+	Serialize: func(value interface{}) interface{} {
+		if v, ok := value.(*int64); ok && v != nil {
+			value = *v
+		}
+		v, ok := value.(int64)
+		if !ok {
+			return nil
+		}
+		return v
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case int:
+			return int64(v)
+		case float64:
+			return int64(v)
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil
+			}
+			return int64(n)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		var s string
+		switch v := valueAST.(type) {
+		case *ast.IntValue:
+			s = v.Value
+		case *ast.StringValue:
+			s = v.Value
+		default:
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return int64(n)
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("JSON", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.scalars = map[string]goScalar{"Test": wellKnownScalars["JSON"]}
+
+		g.generateScalar("Test", false, nil, ts)
+
+		ex := []byte(`var TestType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:         "Test",
+	Serialize:    func(value interface{}) interface{} { return value },
+	ParseValue:   func(value interface{}) interface{} { return value },
+	ParseLiteral: parseJSONLiteral,
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+		if !g.jsonLiteral {
+			subT.Fatal("expected parseJSONLiteral to be required")
+		}
+	})
+
+	t.Run("URL", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.scalars = map[string]goScalar{"Test": wellKnownScalars["URL"]}
+
+		g.generateScalar("Test", false, nil, ts)
+
+		ex := []byte(`var TestType = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Test",
+	Serialize: func(value interface{}) interface{} {
+		if v, ok := value.(*url.URL); ok && v != nil {
+			value = *v
+		}
+		v, ok := value.(url.URL)
+		if !ok {
+			return nil
+		}
+		return v.String()
+	},
+	ParseValue: func(value interface{}) interface{} {
+		s, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		v, err := url.Parse(s)
+		if err != nil {
+			return nil
+		}
+		return *v
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		s, ok := valueAST.(*ast.StringValue)
+		if !ok {
+			return nil
+		}
+		v, err := url.Parse(s.Value)
+		if err != nil {
+			return nil
+		}
+		return *v
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("WellKnownOptIn", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "wellknown", strings.NewReader(`scalar UUID
+
+type Query {
+	id: UUID
+}`), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		for opts, mapped := range map[string]bool{"": false, `{"wellKnownScalars": ["UUID"]}`: true} {
+			g := &Generator{}
+
+			var b bytes.Buffer
+			ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+			if err = g.Generate(ctx, doc, opts); err != nil {
+				subT.Error(err)
+				return
+			}
+
+			if imported := strings.Contains(b.String(), `"github.com/google/uuid"`); imported != mapped {
+				subT.Fatalf("expected UUID to be mapped: %t, with options: %q", mapped, opts)
+			}
+		}
+	})
+}
+
+func TestParseGoScalar(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Ex   goScalar
+		Err  bool
+	}{
+		{Name: "int64", Ex: goScalar{typ: "int64", kind: "int", bits: "64"}},
+		{Name: "uint", Ex: goScalar{typ: "uint", kind: "uint", bits: "0"}},
+		{Name: "float32", Ex: goScalar{typ: "float32", kind: "float", bits: "32"}},
+		{Name: "string", Ex: goScalar{typ: "string", kind: "string", bits: "0"}},
+		{Name: "Local", Ex: goScalar{typ: "Local"}},
+		{Name: "interface{}", Ex: goScalar{typ: "interface{}", kind: "json"}},
+		{Name: "time.Time", Ex: goScalar{path: "time", typ: "time.Time"}},
+		{Name: "github.com/google/uuid.UUID", Ex: goScalar{path: "github.com/google/uuid", typ: "uuid.UUID"}},
+		{Name: "github.com/shopspring/decimal/v2.Decimal", Ex: goScalar{path: "github.com/shopspring/decimal/v2", typ: "decimal.Decimal"}},
+		{Name: "github.com/google/uuid", Err: true},
+		{Name: "time.", Err: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			s, err := parseGoScalar("Test", testCase.Name)
+			if testCase.Err {
+				if err == nil {
+					subT.Fatalf("expected an error, but got: %v", s)
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if s != testCase.Ex {
+				subT.Fatalf("expected: %v, but got: %v", testCase.Ex, s)
+			}
+		})
+	}
+}
+
 func TestObject(t *testing.T) {
 	g := &Generator{}
 
@@ -133,19 +391,19 @@ func TestObject(t *testing.T) {
 
 		g.generateObject("Test", false, nil, ts)
 
-		ex := []byte(`NewObject(graphql.ObjectConfig{
+		ex := []byte(`var TestType = graphql.NewObject(graphql.ObjectConfig{
 	Name: "Test",
 	Fields: graphql.Fields{
 		"one": &graphql.Field{
-			Type: graphql.Int,
+			Type:    graphql.Int,
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
 		},
 		"str": &graphql.Field{
-			Type: graphql.String,
+			Type:    graphql.String,
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
 		},
 		"list": &graphql.Field{
-			Type: graphql.NewList(TestType),
+			Type:    graphql.NewList(TestType),
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
 		},
 	},
@@ -184,7 +442,7 @@ func TestObject(t *testing.T) {
 
 		g.generateObject("Test", false, nil, ts)
 
-		ex := []byte(`NewObject(graphql.ObjectConfig{
+		ex := []byte(`var TestType = graphql.NewObject(graphql.ObjectConfig{
 	Name: "Test",
 	Interfaces: []*graphql.Interface{
 		AType,
@@ -192,15 +450,15 @@ func TestObject(t *testing.T) {
 	},
 	Fields: graphql.Fields{
 		"one": &graphql.Field{
-			Type: graphql.Int,
+			Type:    graphql.Int,
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
 		},
 		"str": &graphql.Field{
-			Type: graphql.String,
+			Type:    graphql.String,
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
 		},
 		"list": &graphql.Field{
-			Type: graphql.NewList(TestType),
+			Type:    graphql.NewList(TestType),
 			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
 		},
 	},
@@ -237,7 +495,7 @@ func TestInterface(t *testing.T) {
 
 	g.generateInterface("Test", false, nil, ts)
 
-	ex := []byte(`NewInterface(graphql.InterfaceConfig{
+	ex := []byte(`var TestType = graphql.NewInterface(graphql.InterfaceConfig{
 	Name: "Test",
 	Fields: graphql.Fields{
 		"one": &graphql.Field{
@@ -267,19 +525,238 @@ func TestUnion(t *testing.T) {
 
 	g.generateUnion("Test", false, nil, ts)
 
-	ex := []byte(`NewUnion(graphql.UnionConfig{
+	ex := []byte(`var TestType = graphql.NewUnion(graphql.UnionConfig{
 	Name: "Test",
 	Types: []*graphql.Object{
 		AType,
 		BType,
 	},
-	ResolveType: func(p graphql.ResolveParams) *graphql.Object { return nil }, // TODO
 })
 `)
 
 	compareBytes(t, ex, g.Bytes())
 }
 
+func TestResolveTypes(t *testing.T) {
+	gqlSrc := `interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+}
+
+type Bot implements Node {
+	id: ID!
+}
+
+union Actor = User`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "resolveTypes", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g := &Generator{}
+
+	t.Run("Implementers", func(subT *testing.T) {
+		impls := findImplementers(doc.Types)
+		if len(impls) != 2 || strings.Join(impls["Node"], ",") != "User,Bot" || strings.Join(impls["Actor"], ",") != "User" {
+			subT.Fatalf("unexpected implementers: %v", impls)
+		}
+	})
+
+	t.Run("SingleMember", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+
+		g.generateUnion("Actor", false, nil, doc.Types[3].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec)
+
+		ex := []byte(`var ActorType = graphql.NewUnion(graphql.UnionConfig{
+	Name:  "Actor",
+	Types: []*graphql.Object{UserType},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("Models", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.models = true
+		g.implementers = findImplementers(doc.Types)
+
+		g.printResolveTypes([]string{"Node", "Actor"})
+
+		ex := []byte(`func init() {
+	NodeType.ResolveType = func(p graphql.ResolveTypeParams) *graphql.Object {
+		switch p.Value.(type) {
+		case *User:
+			return UserType
+		case *Bot:
+			return BotType
+		}
+		if v, ok := p.Value.(map[string]interface{}); ok {
+			switch v["__typename"] {
+			case "User":
+				return UserType
+			case "Bot":
+				return BotType
+			}
+		}
+		return nil
+	}
+	ActorType.ResolveType = func(p graphql.ResolveTypeParams) *graphql.Object {
+		switch p.Value.(type) {
+		case *User:
+			return UserType
+		}
+		if v, ok := p.Value.(map[string]interface{}); ok {
+			switch v["__typename"] {
+			case "User":
+				return UserType
+			}
+		}
+		return nil
+	}
+}
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+}
+
+func TestSubscriptions(t *testing.T) {
+	gqlSrc := `schema {
+	query: Query
+	subscription: Subscription
+}
+
+type Query {
+	hello: String
+}
+
+type Subscription {
+	ticks: Int!
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "subscriptions", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ts := doc.Types[2].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+
+	g := &Generator{}
+
+	t.Run("Subscribe", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.resolvers = true
+		g.roots = rootTypes(doc.Schema)
+		g.subscription = rootOpType(doc.Schema, "subscription")
+
+		g.generateObject("Subscription", false, nil, ts)
+
+		ex := []byte(`var SubscriptionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Subscription",
+	Fields: graphql.Fields{
+		"ticks": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Int),
+			Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+				if SubscriptionResolvers == nil {
+					return nil, errors.New("no subscription resolver for Subscription.ticks")
+				}
+				events, err := SubscriptionResolvers.Ticks(p.Context)
+				if err != nil {
+					return nil, err
+				}
+				c := make(chan interface{})
+				go func() {
+					defer close(c)
+					for {
+						select {
+						case e, ok := <-events:
+							if !ok {
+								return
+							}
+							select {
+							case c <- e:
+							case <-p.Context.Done():
+								return
+							}
+						case <-p.Context.Done():
+							return
+						}
+					}
+				}()
+				return c, nil
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source, nil },
+		},
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("Stub", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.subscription = rootOpType(doc.Schema, "subscription")
+
+		g.generateObject("Subscription", false, nil, ts)
+
+		ex := []byte(`var SubscriptionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Subscription",
+	Fields: graphql.Fields{
+		"ticks": &graphql.Field{
+			Type:      graphql.NewNonNull(graphql.Int),
+			Subscribe: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+			Resolve:   func(p graphql.ResolveParams) (interface{}, error) { return p.Source, nil },
+		},
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("Resolver", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.resolvers = true
+		g.roots = rootTypes(doc.Schema)
+		g.subscription = rootOpType(doc.Schema, "subscription")
+
+		if err := g.generateResolvers(doc.Types[2:], false); err != nil {
+			subT.Error(err)
+			return
+		}
+
+		ex := []byte(`
+// SubscriptionResolver subscribes to the fields of Subscription. The event channels
+// must be closed by the resolver, at the latest when ctx is done.
+type SubscriptionResolver interface {
+	Ticks(ctx context.Context) (<-chan int, error)
+}
+
+// SubscriptionResolvers subscribes to the fields of Subscription. If nil, subscriptions fail.
+var SubscriptionResolvers SubscriptionResolver
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+}
+
 func TestEnum(t *testing.T) {
 	g := &Generator{}
 
@@ -297,7 +774,7 @@ func TestEnum(t *testing.T) {
 
 	g.generateEnum("Test", false, nil, ts)
 
-	ex := []byte(`NewEnum(graphql.EnumConfig{
+	ex := []byte(`var TestType = graphql.NewEnum(graphql.EnumConfig{
 	Name: "Test",
 	Values: graphql.EnumValueConfigMap{
 		"A": &graphql.EnumValueConfig{
@@ -316,48 +793,119 @@ func TestEnum(t *testing.T) {
 	compareBytes(t, ex, g.Bytes())
 }
 
-func TestInput(t *testing.T) {
+func TestDeprecated(t *testing.T) {
+	gqlSrc := `type Test {
+	old: Int @deprecated
+	older: Int @deprecated(reason: "use new")
+}
+
+enum Direction {
+	NORTH @deprecated(reason: "use UP")
+	UP
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "deprecated", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
 	g := &Generator{}
 
-	t.Run("NoDefaults", func(subT *testing.T) {
+	t.Run("Field", func(subT *testing.T) {
 		g.Lock()
 		defer g.Unlock()
 		g.Reset()
 
-		ts := &ast.TypeSpec{Type: &ast.TypeSpec_Input{
-			Input: &ast.InputType{
-				Fields: &ast.InputValueList{
-					List: []*ast.InputValue{
-						{
-							Name: &ast.Ident{Name: "one"},
-							Type: &ast.InputValue_Ident{Ident: &ast.Ident{Name: "Int"}},
-						},
-						{
-							Name: &ast.Ident{Name: "str"},
-							Type: &ast.InputValue_Ident{Ident: &ast.Ident{Name: "String"}},
-						},
-						{
-							Name: &ast.Ident{Name: "list"},
-							Type: &ast.InputValue_List{List: &ast.List{Type: &ast.List_Ident{Ident: &ast.Ident{Name: "Test"}}}},
-						},
-					},
-				},
-			},
-		}}
-
-		g.generateInput("Test", false, nil, ts)
+		g.generateObject("Test", false, nil, doc.Types[0].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec)
 
-		ex := []byte(`NewInputObject(graphql.InputObjectConfig{
+		ex := []byte(`var TestType = graphql.NewObject(graphql.ObjectConfig{
 	Name: "Test",
-	Fields: graphql.InputObjectConfigFieldMap{
-		"one": &graphql.InputObjectFieldConfig{
-			Type: graphql.Int,
+	Fields: graphql.Fields{
+		"old": &graphql.Field{
+			Type:              graphql.Int,
+			Resolve:           func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+			DeprecationReason: graphql.DefaultDeprecationReason,
 		},
-		"str": &graphql.InputObjectFieldConfig{
-			Type: graphql.String,
+		"older": &graphql.Field{
+			Type:              graphql.Int,
+			Resolve:           func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+			DeprecationReason: "use new",
 		},
-		"list": &graphql.InputObjectFieldConfig{
-			Type: graphql.NewList(TestType),
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("EnumValue", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+
+		g.generateEnum("Direction", false, nil, doc.Types[1].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec)
+
+		ex := []byte(`var DirectionType = graphql.NewEnum(graphql.EnumConfig{
+	Name: "Direction",
+	Values: graphql.EnumValueConfigMap{
+		"NORTH": &graphql.EnumValueConfig{
+			Value:             "NORTH",
+			DeprecationReason: "use UP",
+		},
+		"UP": &graphql.EnumValueConfig{
+			Value: "UP",
+		},
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+}
+
+func TestInput(t *testing.T) {
+	g := &Generator{}
+
+	t.Run("NoDefaults", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+
+		ts := &ast.TypeSpec{Type: &ast.TypeSpec_Input{
+			Input: &ast.InputType{
+				Fields: &ast.InputValueList{
+					List: []*ast.InputValue{
+						{
+							Name: &ast.Ident{Name: "one"},
+							Type: &ast.InputValue_Ident{Ident: &ast.Ident{Name: "Int"}},
+						},
+						{
+							Name: &ast.Ident{Name: "str"},
+							Type: &ast.InputValue_Ident{Ident: &ast.Ident{Name: "String"}},
+						},
+						{
+							Name: &ast.Ident{Name: "list"},
+							Type: &ast.InputValue_List{List: &ast.List{Type: &ast.List_Ident{Ident: &ast.Ident{Name: "Test"}}}},
+						},
+					},
+				},
+			},
+		}}
+
+		g.generateInput("Test", false, nil, ts)
+
+		ex := []byte(`var TestType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "Test",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"one": &graphql.InputObjectFieldConfig{
+			Type: graphql.Int,
+		},
+		"str": &graphql.InputObjectFieldConfig{
+			Type: graphql.String,
+		},
+		"list": &graphql.InputObjectFieldConfig{
+			Type: graphql.NewList(TestType),
 		},
 	},
 })
@@ -417,19 +965,19 @@ func TestInput(t *testing.T) {
 
 		g.generateInput("Test", false, nil, ts)
 
-		ex := []byte(`NewInputObject(graphql.InputObjectConfig{
+		ex := []byte(`var TestType = graphql.NewInputObject(graphql.InputObjectConfig{
 	Name: "Test",
 	Fields: graphql.InputObjectConfigFieldMap{
 		"one": &graphql.InputObjectFieldConfig{
-			Type: graphql.Int,
+			Type:         graphql.Int,
 			DefaultValue: 1,
 		},
 		"str": &graphql.InputObjectFieldConfig{
-			Type: graphql.String,
+			Type:         graphql.String,
 			DefaultValue: "hello",
 		},
 		"list": &graphql.InputObjectFieldConfig{
-			Type: graphql.NewList(graphql.Int),
+			Type:         graphql.NewList(graphql.Int),
 			DefaultValue: []interface{}{1, 2, 3},
 		},
 	},
@@ -440,6 +988,110 @@ func TestInput(t *testing.T) {
 	})
 }
 
+func TestDefaultValues(t *testing.T) {
+	gqlSrc := `type Query {
+	f(dir: Direction = NORTH, n: Int = null, grid: [[Int]] = [[1], [2, 3]], one: [Int] = 4, pt: Point = {x: 1, tags: [A]}, scale: Float = 2, id: ID = 7, big: Int64 = 5): String
+}
+
+enum Direction {
+	NORTH
+}
+
+enum Tag {
+	A
+}
+
+input Point {
+	x: Float!
+	y: Float = 0.5
+	tags: [Tag!]
+}
+
+scalar Int64`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "defaults", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g := &Generator{}
+	g.typeSpecs = make(map[string]*ast.TypeSpec)
+	for _, d := range doc.Types {
+		ts := d.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+		g.typeSpecs[ts.Name.Name] = ts
+	}
+	g.scalars = map[string]goScalar{"Int64": wellKnownScalars["Int64"]}
+	query := doc.Types[0].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+
+	t.Run("Values", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Buffer.Reset()
+		g.models = false
+
+		b := newBuilder()
+		args := g.argsConfig(b, query.Type.(*ast.TypeSpec_Object).Object.Fields.List[0].Args, false)
+		g.printDecl(b, b.varDecl("args", args))
+
+		ex := []byte(`var args = graphql.FieldConfigArgument{
+	"dir": &graphql.ArgumentConfig{
+		Type:         DirectionType,
+		DefaultValue: "NORTH",
+	},
+	"n": &graphql.ArgumentConfig{
+		Type:         graphql.Int,
+		DefaultValue: nil,
+	},
+	"grid": &graphql.ArgumentConfig{
+		Type:         graphql.NewList(graphql.NewList(graphql.Int)),
+		DefaultValue: []interface{}{[]interface{}{1}, []interface{}{2, 3}},
+	},
+	"one": &graphql.ArgumentConfig{
+		Type:         graphql.NewList(graphql.Int),
+		DefaultValue: []interface{}{4},
+	},
+	"pt": &graphql.ArgumentConfig{
+		Type:         PointType,
+		DefaultValue: map[string]interface{}{"x": 1.0, "y": 0.5, "tags": []interface{}{"A"}},
+	},
+	"scale": &graphql.ArgumentConfig{
+		Type:         graphql.Float,
+		DefaultValue: 2.0,
+	},
+	"id": &graphql.ArgumentConfig{
+		Type:         graphql.ID,
+		DefaultValue: "7",
+	},
+	"big": &graphql.ArgumentConfig{
+		Type:         Int64Type,
+		DefaultValue: Int64Type.ParseValue(5),
+	},
+}
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("Models", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Buffer.Reset()
+		g.models = true
+
+		b := newBuilder()
+		args := g.argsConfig(b, query.Type.(*ast.TypeSpec_Object).Object.Fields.List[0].Args, false)
+		g.printDecl(b, b.varDecl("args", args))
+
+		out := g.String()
+		for _, ex := range []string{"DefaultValue: DirectionNorth,", `"tags": []interface{}{TagA}`} {
+			if !strings.Contains(out, ex) {
+				subT.Fatalf("expected %s in: %s", ex, out)
+			}
+		}
+	})
+}
+
 func TestDirective(t *testing.T) {
 	g := &Generator{}
 
@@ -460,7 +1112,7 @@ func TestDirective(t *testing.T) {
 
 		g.generateDirective("Test", false, nil, ts)
 
-		ex := []byte(`NewDirective(graphql.DirectiveConfig{
+		ex := []byte(`var TestDirective = graphql.NewDirective(graphql.DirectiveConfig{
 	Name: "Test",
 	Locations: []string{
 		"QUERY",
@@ -473,6 +1125,30 @@ func TestDirective(t *testing.T) {
 		compareBytes(subT, ex, g.Bytes())
 	})
 
+	t.Run("SingleLocation", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+
+		ts := &ast.TypeSpec{Type: &ast.TypeSpec_Directive{
+			Directive: &ast.DirectiveType{
+				Locs: []*ast.DirectiveLocation{
+					{Loc: ast.DirectiveLocation_FIELD},
+				},
+			},
+		}}
+
+		g.generateDirective("Test", false, nil, ts)
+
+		ex := []byte(`var TestDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:      "Test",
+	Locations: []string{"FIELD"},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
 	t.Run("WithArgs", func(subT *testing.T) {
 		g.Lock()
 		defer g.Unlock()
@@ -517,7 +1193,7 @@ func TestDirective(t *testing.T) {
 
 		g.generateDirective("Test", false, nil, ts)
 
-		ex := []byte(`NewDirective(graphql.DirectiveConfig{
+		ex := []byte(`var TestDirective = graphql.NewDirective(graphql.DirectiveConfig{
 	Name: "Test",
 	Locations: []string{
 		"QUERY",
@@ -526,15 +1202,15 @@ func TestDirective(t *testing.T) {
 	},
 	Args: graphql.FieldConfigArgument{
 		"one": &graphql.ArgumentConfig{
-			Type: graphql.Int,
+			Type:         graphql.Int,
 			DefaultValue: 1,
 		},
 		"str": &graphql.ArgumentConfig{
-			Type: graphql.NewNonNull(graphql.String),
+			Type:         graphql.NewNonNull(graphql.String),
 			DefaultValue: "hello",
 		},
 		"list": &graphql.ArgumentConfig{
-			Type: graphql.NewList(graphql.Int),
+			Type:         graphql.NewList(graphql.Int),
 			DefaultValue: []interface{}{1, 2, 3},
 		},
 	},
@@ -545,100 +1221,2533 @@ func TestDirective(t *testing.T) {
 	})
 }
 
-type testCtx struct {
-	io.Writer
-}
-
-func (ctx testCtx) Open(filename string) (io.WriteCloser, error) { return ctx, nil }
-
-func (ctx testCtx) Close() error { return nil }
+func TestDescriptions(t *testing.T) {
+	gqlSrc := `"""
+  Query is the root.
 
-func TestGenerator_Generate(t *testing.T) {
-	g := &Generator{}
+    Indented \\ back\slash
+  Done.
+"""
+type Query {
+	"""
+	Hello
+	world
+	"""
+	a("tab\there \u00e9" x: String = "a\\b\n"): String @deprecated(reason: "use \\b")
+	"""
+	Contains ` + "`backticks`" + `
+	and newlines.
+	"""
+	b: String
+}`
 
-	var b bytes.Buffer
-	ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
-	err := g.Generate(ctx, testDoc, `{"descriptions": true}`)
+	doc, err := parser.ParseDoc(token.NewDocSet(), "descriptions", strings.NewReader(gqlSrc), 0)
 	if err != nil {
 		t.Error(err)
 		return
 	}
 
-	ex, err := ioutil.ReadAll(exDoc)
-	if err != nil {
-		t.Error(err)
-		return
-	}
+	g := &Generator{}
+	g.generateObject("Query", true, doc.Types[0].Doc, doc.Types[0].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec)
 
-	compareBytes(t, ex, b.Bytes())
+	ex := []byte(`var QueryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"a": &graphql.Field{
+			Type: graphql.String,
+			Args: graphql.FieldConfigArgument{
+				"x": &graphql.ArgumentConfig{
+					Type:         graphql.String,
+					DefaultValue: "a\\b\n",
+					Description:  "tab\there é",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+			Description: ` + "`Hello\nworld`" + `,
+			DeprecationReason: "use \\b",
+		},
+		"b": &graphql.Field{
+			Type:        graphql.String,
+			Resolve:     func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+			Description: "Contains ` + "`backticks`" + `\nand newlines.",
+		},
+	},
+	Description: ` + "`Query is the root.\n\n  Indented \\\\ back\\slash\nDone.`" + `,
+})
+`)
+
+	compareBytes(t, ex, g.Bytes())
 }
 
-func BenchmarkGenerator_Generate(b *testing.B) {
-	g := &Generator{}
+func TestStringValue(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Lit  string
+		Ex   string
+	}{
+		{Name: "Plain", Lit: `"abc"`, Ex: "abc"},
+		{Name: "Escapes", Lit: `"a\"b\\c\/d\n\t\u00e9"`, Ex: "a\"b\\c/d\n\té"},
+		{Name: "BlockString", Lit: "\"\"\"\n    a\n\n      b\\n\n    c \\\"\"\"\n  \"\"\"", Ex: "a\n\n  b\\n\nc \"\"\""},
+		{Name: "BlockStringSingleLine", Lit: `"""a \n b"""`, Ex: `a \n b`},
+	}
 
-	var buf bytes.Buffer
-	ctx := compiler.WithContext(context.Background(), testCtx{Writer: &buf})
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			if v := stringValue(testCase.Lit); v != testCase.Ex {
+				subT.Fatalf("expected: %q, but got: %q", testCase.Ex, v)
+			}
+		})
+	}
+}
 
-	for i := 0; i < b.N; i++ {
-		buf.Reset()
+func TestGoString(t *testing.T) {
+	testCases := []struct {
+		Name string
+		S    string
+		Ex   string
+	}{
+		{Name: "Plain", S: "abc", Ex: `"abc"`},
+		{Name: "Quotes", S: `a "b" \c`, Ex: `"a \"b\" \\c"`},
+		{Name: "MultiLine", S: "a \"b\"\n\tc \\d", Ex: "`a \"b\"\n\tc \\d`"},
+		{Name: "MultiLineBacktick", S: "a\n`b`", Ex: `"a\n` + "`b`" + `"`},
+		{Name: "TrailingNewline", S: "a\nb\n", Ex: `"a\nb\n"`},
+	}
 
-		err := g.Generate(ctx, testDoc, "")
-		if err != nil {
-			b.Error(err)
-			return
-		}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			if s := goString(testCase.S); s != testCase.Ex {
+				subT.Fatalf("expected: %s, but got: %s", testCase.Ex, s)
+			}
+		})
 	}
 }
 
-func ExampleGenerator_Generate() {
-	g := new(Generator)
+func TestCycles(t *testing.T) {
+	gqlSrc := `type User {
+	friends: [User]
+}
 
-	gqlSrc := `schema {
-	query: Query
+type A {
+	b: B
+	x: Int
 }
 
-"Query represents the queries this example provides."
-type Query {
-	hello: String
+type B {
+	a: A!
+}
+
+type C {
+	a: A
 }`
 
-	doc, err := parser.ParseDoc(token.NewDocSet(), "example", strings.NewReader(gqlSrc), 0)
+	doc, err := parser.ParseDoc(token.NewDocSet(), "cycles", strings.NewReader(gqlSrc), 0)
 	if err != nil {
-		log.Fatal(err)
+		t.Error(err)
 		return
 	}
 
-	var b bytes.Buffer
-	ctx := compiler.WithContext(context.Background(), &testCtx{Writer: &b}) // Pass in an actual
-	err = g.Generate(ctx, doc, `{"descriptions": true}`)
-	if err != nil {
-		log.Fatal(err)
-		return
+	cycles := findCycles(doc.Types)
+	if len(cycles) != 3 {
+		t.Fatalf("expected 3 types in cycles but got: %v", cycles)
+	}
+	if _, ok := cycles["C"]; ok {
+		t.Fatalf("expected C to not be part of a cycle")
+	}
+	if cycles["A"] != cycles["B"] || cycles["A"] == cycles["User"] {
+		t.Fatalf("expected A and B to form a cycle apart from User but got: %v", cycles)
 	}
-	fmt.Println(b.String())
 
-	// Output:
-	// package main
-	//
-	// import "github.com/graphql-go/graphql"
-	//
-	// var Schema graphql.Schema
-	//
-	// var QueryType = graphql.NewObject(graphql.ObjectConfig{
-	// 	Name: "Query",
-	//	Fields: graphql.Fields{
-	//		"hello": &graphql.Field{
-	//			Type: graphql.String,
-	//			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
-	//		},
-	//	},
-	//	Description: "Query represents the queries this example provides.",
-	// })
-	//
-	// func init() {
-	//	var err error
-	//	Schema, err = graphql.NewSchema(graphql.SchemaConfig{
-	//		Query: QueryType,
+	g := &Generator{}
+	g.Reset()
+	g.cycles = cycles
+
+	ts := doc.Types[1].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+	g.generateObject("A", false, nil, ts)
+	g.printDeferred()
+
+	ex := []byte(`var AType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "A",
+	Fields: graphql.Fields{
+		"x": &graphql.Field{
+			Type:    graphql.Int,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+		},
+	},
+})
+func init() {
+	AType.AddFieldConfig("b", &graphql.Field{
+		Type:    BType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+	})
+}
+`)
+
+	compareBytes(t, ex, g.Bytes())
+}
+
+func TestMergeExtensions(t *testing.T) {
+	t.Run("Merged", func(subT *testing.T) {
+		gqlSrc := `schema {
+	query: Query
+}
+
+extend schema {
+	mutation: Mutation
+}
+
+extend type Query implements Node {
+	b: Int
+}
+
+type Query {
+	a: Int
+}
+
+type Mutation {
+	a: Int
+}
+
+interface Node {
+	id: ID
+}
+
+extend enum Direction {
+	SOUTH
+}
+
+enum Direction {
+	NORTH
+}
+
+union Result = Query
+
+extend union Result = Mutation
+
+input Point {
+	x: Float
+}
+
+extend input Point {
+	y: Float
+}`
+
+		doc, err := parser.ParseDoc(token.NewDocSet(), "extend", strings.NewReader(gqlSrc), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		schema, types, err := mergeExtensions(doc)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		if len(types) != 7 {
+			subT.Fatalf("expected 7 types but got: %d", len(types))
+		}
+
+		rootOps := schema.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Schema).Schema.RootOps.List
+		if len(rootOps) != 2 {
+			subT.Fatalf("expected 2 root operations but got: %d", len(rootOps))
+		}
+
+		query := types[1].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Object).Object
+		if len(query.Fields.List) != 2 || len(query.Interfaces) != 1 {
+			subT.Fatalf("expected Query to have 2 fields and 1 interface but got: %d, %d", len(query.Fields.List), len(query.Interfaces))
+		}
+
+		enum := types[4].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Enum).Enum
+		if len(enum.Values.List) != 2 {
+			subT.Fatalf("expected Direction to have 2 values but got: %d", len(enum.Values.List))
+		}
+
+		union := types[5].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Union).Union
+		if len(union.Members) != 2 {
+			subT.Fatalf("expected Result to have 2 members but got: %d", len(union.Members))
+		}
+
+		input := types[6].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Input).Input
+		if len(input.Fields.List) != 2 {
+			subT.Fatalf("expected Point to have 2 fields but got: %d", len(input.Fields.List))
+		}
+
+		// The original document must be left untouched
+		orig := doc.Types[3].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Object).Object
+		if len(orig.Fields.List) != 1 || len(orig.Interfaces) != 0 {
+			subT.Fatalf("expected original Query to be unchanged")
+		}
+	})
+
+	t.Run("Undeclared", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "extend", strings.NewReader(`extend type Query { a: Int }`), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		g := &Generator{}
+
+		var b bytes.Buffer
+		ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+		err = g.Generate(ctx, doc, "")
+		if _, ok := err.(compiler.GeneratorError); !ok {
+			subT.Fatalf("expected a generator error but got: %v", err)
+		}
+	})
+
+	testCases := []struct {
+		Name string
+		Src  string
+		Err  string
+	}{
+		{
+			Name: "Field",
+			Src: `type Query {
+	a: Int
+}
+
+extend type Query {
+	a: Int
+}`,
+			Err: "duplicate field in extension of Query: a",
+		},
+		{
+			Name: "Interface",
+			Src: `interface Node {
+	id: ID
+}
+
+type Query implements Node {
+	id: ID
+}
+
+extend type Query implements Node`,
+			Err: "duplicate interface in extension of Query: Node",
+		},
+		{
+			Name: "EnumValue",
+			Src: `enum Direction {
+	NORTH
+}
+
+extend enum Direction {
+	SOUTH
+	NORTH
+}`,
+			Err: "duplicate value in extension of Direction: NORTH",
+		},
+		{
+			Name: "UnionMember",
+			Src: `type A {
+	a: Int
+}
+
+union Result = A
+
+extend union Result = A`,
+			Err: "duplicate member in extension of Result: A",
+		},
+		{
+			Name: "InputField",
+			Src: `input Point {
+	x: Float
+}
+
+extend input Point {
+	x: Float
+}`,
+			Err: "duplicate field in extension of Point: x",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("Duplicate"+testCase.Name, func(subT *testing.T) {
+			doc, err := parser.ParseDoc(token.NewDocSet(), "extend", strings.NewReader(testCase.Src), 0)
+			if err != nil {
+				subT.Error(err)
+				return
+			}
+
+			_, _, err = mergeExtensions(doc)
+			if err == nil || err.Error() != testCase.Err {
+				subT.Fatalf("expected error: %s, but got: %v", testCase.Err, err)
+			}
+		})
+	}
+}
+
+func TestCompilerTypes(t *testing.T) {
+	gqlSrc := `schema {
+	query: Query
+}
+
+type Query {
+	a: Int
+	b: skip
+}
+
+type skip {
+	a: Int
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "compiler", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	doc.Types = append(doc.Types, compiler.Types...)
+
+	g := &Generator{}
+
+	var b bytes.Buffer
+	ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+	err = g.Generate(ctx, doc, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, name := range []string{"IntType", "includeDirective", "deprecatedDirective", "goDirective", "GoOptionsType"} {
+		if bytes.Contains(b.Bytes(), []byte(name)) {
+			t.Fatalf("expected compiler type to be skipped: %s", name)
+		}
+	}
+	if !bytes.Contains(b.Bytes(), []byte("var skipType")) {
+		t.Fatalf("expected type named after a builtin directive to be generated, but got:\n%s", b.String())
+	}
+}
+
+func TestGoName(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Ex   string
+	}{
+		{Name: "msg", Ex: "Msg"},
+		{Name: "hasNextPage", Ex: "HasNextPage"},
+		{Name: "id", Ex: "ID"},
+		{Name: "userId", Ex: "UserID"},
+		{Name: "avatar_url", Ex: "AvatarURL"},
+		{Name: "NORTH", Ex: "North"},
+		{Name: "NORTH_EAST", Ex: "NorthEast"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			if name := goName(testCase.Name); name != testCase.Ex {
+				subT.Fatalf("expected: %s, but got: %s", testCase.Ex, name)
+			}
+		})
+	}
+}
+
+func TestGoNameCollisions(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Src  string
+		Opts string
+		Err  string
+	}{
+		{
+			Name: "Blank",
+			Src: `type Query {
+	_: Int
+}`,
+			Opts: `{"models": true}`,
+			Err:  "field Query._ has no Go name",
+		},
+		{
+			Name: "Duplicate",
+			Src: `type Query {
+	user_id: ID
+	userId: ID
+}`,
+			Opts: `{"models": true}`,
+			Err:  "field Query.user_id and field Query.userId both map to the Go name UserID",
+		},
+		{
+			Name: "Method",
+			Src: `interface Node {
+	id: ID
+}
+
+type Query implements Node {
+	id: ID
+	isNode: Boolean
+}`,
+			Opts: `{"models": true}`,
+			Err:  "method Query.IsNode and field Query.isNode both map to the Go name IsNode",
+		},
+		{
+			Name: "Args",
+			Src: `type Query {
+	a(user_id: ID, userId: ID): Int
+}`,
+			Opts: `{"backend": "graph-gophers"}`,
+			Err:  "field Query.a.user_id and field Query.a.userId both map to the Go name UserID",
+		},
+		{
+			Name: "Schema",
+			Src: `type Query {
+	a: Schema
+}
+
+type Schema {
+	a: Int
+}`,
+			Opts: `{"models": true}`,
+			Err:  "the schema and type Schema both map to the Go name Schema",
+		},
+		{
+			Name: "TypeVar",
+			Src: `type Query {
+	a: QueryType
+}
+
+type QueryType {
+	a: Int
+}`,
+			Opts: `{"models": true}`,
+			Err:  "type Query and type QueryType both map to the Go name QueryType",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			doc, err := parser.ParseDoc(token.NewDocSet(), "names", strings.NewReader(testCase.Src), 0)
+			if err != nil {
+				subT.Error(err)
+				return
+			}
+
+			g := &Generator{}
+
+			var b bytes.Buffer
+			ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+			err = g.Generate(ctx, doc, testCase.Opts)
+			gerr, ok := err.(compiler.GeneratorError)
+			if !ok || gerr.Msg != testCase.Err {
+				subT.Fatalf("expected error: %s, but got: %v", testCase.Err, err)
+			}
+		})
+	}
+
+	t.Run("Disabled", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "names", strings.NewReader(`type Query {
+	user_id: ID
+	userId: ID
+}`), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		g := &Generator{}
+
+		var b bytes.Buffer
+		ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+		if err = g.Generate(ctx, doc, ""); err != nil {
+			subT.Fatalf("expected no error without models but got: %v", err)
+		}
+	})
+}
+
+func TestModels(t *testing.T) {
+	gqlSrc := `"Node represents a node."
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	name: String
+	friends: [User!]
+	dir: Direction!
+}
+
+union Result = User
+
+enum Direction {
+	"North is up."
+	NORTH
+	SOUTH_WEST
+}
+
+input Point {
+	x: Float!
+	y: Float
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "models", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g := &Generator{}
+	g.Reset()
+	g.typeSpecs = make(map[string]*ast.TypeSpec)
+	for _, d := range doc.Types {
+		ts := d.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+		g.typeSpecs[ts.Name.Name] = ts
+	}
+
+	if err = g.generateModels(doc.Types, true); err != nil {
+		t.Error(err)
+		return
+	}
+
+	ex := []byte(`
+// Node represents a node.
+type Node interface {
+	IsNode()
+}
+
+type User struct {
+	ID      string    ` + "`json:\"id\"`" + `
+	Name    *string   ` + "`json:\"name\"`" + `
+	Friends []*User   ` + "`json:\"friends\"`" + `
+	Dir     Direction ` + "`json:\"dir\"`" + `
+}
+
+func (*User) IsNode() {}
+
+func (*User) IsResult() {}
+
+type Result interface {
+	IsResult()
+}
+
+type Direction string
+
+const (
+	// North is up.
+	DirectionNorth     Direction = "NORTH"
+	DirectionSouthWest Direction = "SOUTH_WEST"
+)
+
+type Point struct {
+	X float64  ` + "`json:\"x\"`" + `
+	Y *float64 ` + "`json:\"y\"`" + `
+}
+`)
+
+	compareBytes(t, ex, g.Bytes())
+}
+
+func TestResolvers(t *testing.T) {
+	gqlSrc := `schema {
+	query: Query
+}
+
+type Query {
+	echo(text: String!): Echo
+}
+
+type Echo {
+	msg: String!
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "resolvers", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g := &Generator{}
+
+	t.Run("Resolve", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.resolvers = true
+		g.roots = rootTypes(doc.Schema)
+
+		g.generateObject("Query", false, nil, doc.Types[1].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec)
+		g.generateObject("Echo", false, nil, doc.Types[2].Spec.(*ast.TypeDecl_TypeSpec).TypeSpec)
+
+		ex := []byte(`var QueryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"echo": &graphql.Field{
+			Type: EchoType,
+			Args: graphql.FieldConfigArgument{
+				"text": &graphql.ArgumentConfig{
+					Type: graphql.NewNonNull(graphql.String),
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if QueryResolvers == nil {
+					return graphql.DefaultResolveFn(p)
+				}
+				args, err := decodeQueryEchoArgs(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return QueryResolvers.Echo(p.Context, args)
+			},
+		},
+	},
+})
+var EchoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Echo",
+	Fields: graphql.Fields{
+		"msg": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if EchoResolvers == nil {
+					return graphql.DefaultResolveFn(p)
+				}
+				obj, ok := p.Source.(*Echo)
+				if !ok {
+					return nil, fmt.Errorf("Echo.msg: unexpected source %T", p.Source)
+				}
+				return EchoResolvers.Msg(p.Context, obj)
+			},
+		},
+	},
+})
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+
+	t.Run("Interfaces", func(subT *testing.T) {
+		g.Lock()
+		defer g.Unlock()
+		g.Reset()
+		g.resolvers = true
+		g.roots = rootTypes(doc.Schema)
+
+		if err := g.generateResolvers(doc.Types, false); err != nil {
+			subT.Error(err)
+			return
+		}
+
+		ex := []byte(`
+// QueryEchoArgs contains the arguments of Query.echo.
+type QueryEchoArgs struct {
+	Text string ` + "`json:\"text\"`" + `
+}
+
+// QueryResolver resolves the fields of Query.
+type QueryResolver interface {
+	Echo(ctx context.Context, args QueryEchoArgs) (*Echo, error)
+}
+
+// QueryResolvers resolves the fields of Query. If nil, fields are resolved from their source value.
+var QueryResolvers QueryResolver
+
+// EchoResolver resolves the fields of Echo.
+type EchoResolver interface {
+	Msg(ctx context.Context, obj *Echo) (string, error)
+}
+
+// EchoResolvers resolves the fields of Echo. If nil, fields are resolved from their source value.
+var EchoResolvers EchoResolver
+
+// decodeQueryEchoArgs decodes the arguments of Query.echo.
+func decodeQueryEchoArgs(args map[string]interface{}) (res QueryEchoArgs, err error) {
+	if v := args["text"]; v != nil {
+		x0, ok := v.(string)
+		if !ok {
+			return res, fmt.Errorf("Query.echo(text): expected String but got %T", v)
+		}
+		res.Text = x0
+	} else {
+		return res, fmt.Errorf("Query.echo(text): must not be null")
+	}
+	return
+}
+`)
+
+		compareBytes(subT, ex, g.Bytes())
+	})
+}
+
+func TestDecoders(t *testing.T) {
+	gqlSrc := `type Query {
+	search(id: ID, terms: [String!], dir: Direction, at: Point): String
+}
+
+enum Direction {
+	NORTH
+	SOUTH
+}
+
+input Point {
+	x: Float!
+	tags: [[String]] = ["a"]
+	size: Int! = 1
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "decoders", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g := &Generator{}
+	g.typeSpecs = make(map[string]*ast.TypeSpec)
+	for _, d := range doc.Types {
+		ts := d.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+		g.typeSpecs[ts.Name.Name] = ts
+	}
+
+	if err = g.generateDecoders(doc.Types); err != nil {
+		t.Error(err)
+		return
+	}
+
+	ex := []byte(`
+// decodeQuerySearchArgs decodes the arguments of Query.search.
+func decodeQuerySearchArgs(args map[string]interface{}) (res QuerySearchArgs, err error) {
+	if v := args["id"]; v != nil {
+		var x0 string
+		switch id := v.(type) {
+		case string:
+			x0 = id
+		case int:
+			x0 = strconv.Itoa(id)
+		default:
+			return res, fmt.Errorf("Query.search(id): expected ID but got %T", v)
+		}
+		res.ID = &x0
+	}
+	if v := args["terms"]; v != nil {
+		l0, ok := v.([]interface{})
+		if !ok {
+			l0 = []interface{}{v}
+		}
+		x0 := make([]string, len(l0))
+		for i0, e0 := range l0 {
+			if e0 == nil {
+				return res, fmt.Errorf("Query.search(terms)[%d]: must not be null", i0)
+			}
+			x1, ok := e0.(string)
+			if !ok {
+				return res, fmt.Errorf("Query.search(terms)[%d]: expected String but got %T", i0, e0)
+			}
+			x0[i0] = x1
+		}
+		res.Terms = x0
+	}
+	if v := args["dir"]; v != nil {
+		var x0 Direction
+		switch e := v.(type) {
+		case Direction:
+			x0 = e
+		case string:
+			x0 = Direction(e)
+		default:
+			return res, fmt.Errorf("Query.search(dir): expected Direction but got %T", v)
+		}
+		switch x0 {
+		case DirectionNorth, DirectionSouth:
+		default:
+			return res, fmt.Errorf("Query.search(dir): invalid Direction value %q", x0)
+		}
+		res.Dir = &x0
+	}
+	if v := args["at"]; v != nil {
+		x0, err := decodePoint(v)
+		if err != nil {
+			return res, fmt.Errorf("Query.search(at): %s", err)
+		}
+		res.At = x0
+	}
+	return
+}
+
+// decodePoint decodes a Point input object.
+func decodePoint(val interface{}) (res *Point, err error) {
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected Point but got %T", val)
+	}
+	res = new(Point)
+	if v := m["x"]; v != nil {
+		var x0 float64
+		switch n := v.(type) {
+		case float64:
+			x0 = n
+		case int:
+			x0 = float64(n)
+		default:
+			return res, fmt.Errorf("Point.x: expected Float but got %T", v)
+		}
+		res.X = x0
+	} else {
+		return res, fmt.Errorf("Point.x: must not be null")
+	}
+	if v, ok := m["tags"]; v != nil {
+		l0, ok := v.([]interface{})
+		if !ok {
+			l0 = []interface{}{v}
+		}
+		x0 := make([][]*string, len(l0))
+		for i0, e0 := range l0 {
+			if e0 == nil {
+				continue
+			}
+			l1, ok := e0.([]interface{})
+			if !ok {
+				l1 = []interface{}{e0}
+			}
+			x1 := make([]*string, len(l1))
+			for i1, e1 := range l1 {
+				if e1 == nil {
+					continue
+				}
+				x2, ok := e1.(string)
+				if !ok {
+					return res, fmt.Errorf("Point.tags[%d][%d]: expected String but got %T", i0, i1, e1)
+				}
+				x1[i1] = &x2
+			}
+			x0[i0] = x1
+		}
+		res.Tags = x0
+	} else if !ok {
+		var v interface{} = []interface{}{[]interface{}{"a"}}
+		l0, ok := v.([]interface{})
+		if !ok {
+			l0 = []interface{}{v}
+		}
+		x0 := make([][]*string, len(l0))
+		for i0, e0 := range l0 {
+			if e0 == nil {
+				continue
+			}
+			l1, ok := e0.([]interface{})
+			if !ok {
+				l1 = []interface{}{e0}
+			}
+			x1 := make([]*string, len(l1))
+			for i1, e1 := range l1 {
+				if e1 == nil {
+					continue
+				}
+				x2, ok := e1.(string)
+				if !ok {
+					return res, fmt.Errorf("Point.tags[%d][%d]: expected String but got %T", i0, i1, e1)
+				}
+				x1[i1] = &x2
+			}
+			x0[i0] = x1
+		}
+		res.Tags = x0
+	}
+	if v, ok := m["size"]; v != nil {
+		x0, ok := v.(int)
+		if !ok {
+			return res, fmt.Errorf("Point.size: expected Int but got %T", v)
+		}
+		res.Size = x0
+	} else if !ok {
+		var v interface{} = 1
+		x0, ok := v.(int)
+		if !ok {
+			return res, fmt.Errorf("Point.size: expected Int but got %T", v)
+		}
+		res.Size = x0
+	} else {
+		return res, fmt.Errorf("Point.size: must not be null")
+	}
+	return
+}
+`)
+
+	compareBytes(t, ex, g.Bytes())
+}
+
+type testCtx struct {
+	io.Writer
+}
+
+func (ctx testCtx) Open(filename string) (io.WriteCloser, error) { return ctx, nil }
+
+func (ctx testCtx) Close() error { return nil }
+
+// testFiles is a generator context which keeps every file in memory
+type testFiles map[string]*bytes.Buffer
+
+func (files testFiles) Open(filename string) (io.WriteCloser, error) {
+	b := new(bytes.Buffer)
+	files[filename] = b
+	return testCtx{Writer: b}, nil
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	g := &Generator{}
+
+	var b bytes.Buffer
+	ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+	err := g.Generate(ctx, testDoc, `{"descriptions": true}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ex, err := ioutil.ReadAll(exDoc)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	compareBytes(t, ex, b.Bytes())
+}
+
+func TestFormatSource(t *testing.T) {
+	t.Run("Valid", func(subT *testing.T) {
+		out, err := formatSource([]byte("package main\n\nvar (\nA = 1\nBC = 2\n)\n"))
+		if err != nil {
+			subT.Fatal(err)
+		}
+
+		compareBytes(subT, []byte("package main\n\nvar (\n\tA  = 1\n\tBC = 2\n)\n"), out)
+	})
+
+	t.Run("Invalid", func(subT *testing.T) {
+		_, err := formatSource([]byte("package main\n\nvar A = graphql.NewObject(graphql.ObjectConfig{\n\tName: \"A\"\n})\n"))
+		if err == nil {
+			subT.Fatal("expected an error")
+		}
+
+		if !strings.HasSuffix(err.Error(), "\n\t4: Name: \"A\"") {
+			subT.Fatalf("expected the offending line in: %s", err)
+		}
+	})
+}
+
+func TestGenerator_GenerateNoFormat(t *testing.T) {
+	g := &Generator{}
+
+	var b bytes.Buffer
+	ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+	err := g.Generate(ctx, testDoc, `{"descriptions": true, "format": false}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if bytes.Equal(out, b.Bytes()) {
+		t.Fatal("expected unformatted output")
+	}
+}
+
+// Modules generated packages are built against
+const (
+	graphqlGoModule = "github.com/graphql-go/graphql v0.8.1"
+	gophersModule   = "github.com/graph-gophers/graphql-go v1.5.0"
+)
+
+// buildPackage builds generated files as a package of a temporary module, which requires
+// the given modules. The test is skipped if the modules can't be resolved, e.g. offline.
+func buildPackage(t *testing.T, files testFiles, requires ...string) {
+	goCmd, err := osexec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	dir, err := ioutil.TempDir("", "gqlc-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Generated packages import nothing but the exec package of this module, which
+	// has no dependencies, so this module is replaced by a copy of just that package
+	execDir := filepath.Join(dir, "golang", "exec")
+	if err = os.MkdirAll(execDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "golang", "go.mod"), []byte("module github.com/gqlc/golang\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcs, err := filepath.Glob(filepath.Join("exec", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range srcs {
+		if strings.HasSuffix(src, "_test.go") {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(execDir, filepath.Base(src)), b, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mod bytes.Buffer
+	mod.WriteString("module gentest\n\nrequire (\n")
+	for _, req := range requires {
+		fmt.Fprintf(&mod, "\t%s\n", req)
+	}
+	mod.WriteString(")\n\nreplace github.com/gqlc/golang => ./golang\n")
+	err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), mod.Bytes(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, b := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), b.Bytes(), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := osexec.Command(goCmd, "build", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return
+	}
+
+	// Only errors of the generated files themselves are failures
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "./") {
+			t.Fatalf("generated package doesn't compile:\n%s", out)
+		}
+	}
+	t.Skipf("modules of the generated package can't be resolved:\n%s", out)
+}
+
+func TestGenerator_GenerateBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building generated packages is slow")
+	}
+
+	testCases := []struct {
+		Name     string
+		Opts     string
+		Requires []string
+		Check    string
+	}{
+		{
+			Name:     "graphql-go",
+			Opts:     `{"descriptions": true, "resolvers": true}`,
+			Requires: []string{graphqlGoModule},
+		},
+		{
+			Name:     "graphql-go/per-kind",
+			Opts:     `{"models": true, "layout": "per-kind"}`,
+			Requires: []string{graphqlGoModule},
+		},
+		{
+			Name:     "graph-gophers",
+			Opts:     `{"backend": "graph-gophers", "descriptions": true}`,
+			Requires: []string{gophersModule},
+		},
+		{
+			// The gqlgen module can't be required without network access, so instead
+			// of importing its graphql package, enums and scalars are checked against
+			// copies of its Marshaler and Unmarshaler interfaces.
+			Name: "gqlgen",
+			Opts: `{"backend": "gqlgen", "descriptions": true, "layout": "per-type"}`,
+			Check: `package gentest
+
+import "io"
+
+type marshaler interface {
+	MarshalGQL(w io.Writer)
+}
+
+type unmarshaler interface {
+	UnmarshalGQL(v interface{}) error
+}
+
+var (
+	_ marshaler   = Direction("")
+	_ unmarshaler = new(Direction)
+	_ marshaler   = Version{}
+	_ unmarshaler = new(Version)
+)
+`,
+		},
+		{
+			Name:     "executor",
+			Opts:     `{"backend": "executor", "descriptions": true}`,
+			Requires: []string{"github.com/gqlc/golang v0.0.0"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			files := make(testFiles)
+			opts := strings.TrimSuffix(testCase.Opts, "}") + `, "package": "gentest"}`
+			err := new(Generator).Generate(compiler.WithContext(context.Background(), files), testDoc, opts)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if testCase.Check != "" {
+				files["check.go"] = bytes.NewBufferString(testCase.Check)
+			}
+
+			buildPackage(subT, files, testCase.Requires...)
+		})
+	}
+}
+
+func TestGenerator_GenerateReachableTypes(t *testing.T) {
+	gqlSrc := `schema {
+	query: Query
+}
+
+type Query {
+	node(id: ID!): Node
+}
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	role: Role
+}
+
+enum Role {
+	ADMIN
+}
+
+type Unused {
+	id: ID!
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "reachable", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	testCases := []struct {
+		Name string
+		Opts string
+		Ex   string
+	}{
+		{
+			Name: "All",
+			Opts: `{"format": false}`,
+			Ex: `
+		Types: []graphql.Type{
+			QueryType,
+			NodeType,
+			UserType,
+			RoleType,
+			UnusedType,
+		},
+`,
+		},
+		{
+			Name: "Reachable",
+			Opts: `{"format": false, "reachableTypes": true}`,
+			Ex: `
+		Types: []graphql.Type{
+			QueryType,
+			NodeType,
+			UserType,
+			RoleType,
+		},
+`,
+		},
+	}
+
+	g := &Generator{}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			var b bytes.Buffer
+			ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+			err := g.Generate(ctx, doc, testCase.Opts)
+			if err != nil {
+				subT.Error(err)
+				return
+			}
+
+			if !strings.Contains(b.String(), testCase.Ex) {
+				subT.Fatalf("expected types:%s\nbut got:\n%s", testCase.Ex, b.String())
+			}
+		})
+	}
+}
+
+func TestGenerator_GenerateLibrary(t *testing.T) {
+	libSrc := `interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+}`
+
+	appSrc := `schema {
+	query: Query
+}
+
+type Query {
+	node(id: ID!): Node
+}
+
+type Post implements Node {
+	id: ID!
+}
+
+` + libSrc
+
+	g := &Generator{}
+
+	t.Run("RegisterTypes", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "lib", strings.NewReader(libSrc), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		var b bytes.Buffer
+		ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+		err = g.Generate(ctx, doc, `{"package": "lib"}`)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		ex := `// RegisterTypes adds the types of this package to the given types, by name.
+func RegisterTypes(types map[string]graphql.Type) {
+	types["Node"] = NodeType
+	types["User"] = UserType
+}
+`
+		if !strings.HasSuffix(b.String(), ex) {
+			subT.Fatalf("expected RegisterTypes:\n%s\nbut got:\n%s", ex, b.String())
+		}
+	})
+
+	t.Run("Composed", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "app", strings.NewReader(appSrc), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		var b bytes.Buffer
+		ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+		err = g.Generate(ctx, doc, `{"libraries": {"Node": "example.com/lib", "User": "example.com/lib"}}`)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		out := b.String()
+		if strings.Contains(out, "var UserType") || strings.Contains(out, "var NodeType") {
+			subT.Fatalf("expected types of library to be skipped, but got:\n%s", out)
+		}
+
+		exs := []string{
+			`"example.com/lib"`,
+			`"sort"`,
+			"Type: lib.NodeType,",
+			"Interfaces: []*graphql.Interface{lib.NodeType},",
+			`	resolveNode := lib.NodeType.ResolveType
+	lib.NodeType.ResolveType = func(p graphql.ResolveTypeParams) *graphql.Object {`,
+			"		return resolveNode(p)\n",
+			`	libraryTypes := make(map[string]graphql.Type)
+	lib.RegisterTypes(libraryTypes)
+	names := make([]string, 0, len(libraryTypes))
+	for name := range libraryTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	types := []graphql.Type{
+		QueryType,
+		PostType,
+	}
+	for _, name := range names {
+		types = append(types, libraryTypes[name])
+	}
+`,
+			"		Types: types,\n",
+		}
+		for _, ex := range exs {
+			if !strings.Contains(out, ex) {
+				subT.Fatalf("expected:\n%s\nbut got:\n%s", ex, out)
+			}
+		}
+	})
+}
+
+func TestGenerator_GenerateImplicitSchema(t *testing.T) {
+	g := &Generator{}
+
+	t.Run("RootTypes", func(subT *testing.T) {
+		gqlSrc := `type Mutation {
+	hello(name: String): String
+}
+
+type Query {
+	hello: String
+}`
+
+		doc, err := parser.ParseDoc(token.NewDocSet(), "implicit", strings.NewReader(gqlSrc), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		var b bytes.Buffer
+		ctx := compiler.WithContext(context.Background(), testCtx{Writer: &b})
+		err = g.Generate(ctx, doc, `{"resolvers": true}`)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		exs := []string{
+			"var Schema graphql.Schema\n",
+			`	Schema, err = graphql.NewSchema(graphql.SchemaConfig{
+		Query:    QueryType,
+		Mutation: MutationType,
+`,
+			"	Hello(ctx context.Context, args MutationHelloArgs) (*string, error)\n",
+		}
+		for _, ex := range exs {
+			if !strings.Contains(b.String(), ex) {
+				subT.Fatalf("expected:\n%s\nbut got:\n%s", ex, b.String())
+			}
+		}
+	})
+
+	t.Run("NoQuery", func(subT *testing.T) {
+		gqlSrc := `type Mutation {
+	hello: String
+}`
+
+		doc, err := parser.ParseDoc(token.NewDocSet(), "implicit", strings.NewReader(gqlSrc), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		if schema := implicitSchema(doc.Types); schema != nil {
+			subT.Fatalf("expected no schema, but got: %v", schema)
+		}
+	})
+}
+
+func TestGenerator_GeneratePackage(t *testing.T) {
+	srcs := map[string]string{
+		"schema.gql": `@go(options: {package: "app"})
+
+schema {
+	query: Query
+}
+
+type Query {
+	user(id: ID!): User
+}`,
+		"user.gql": `@go(options: {package: "app"})
+
+type User {
+	id: ID!
+}
+
+extend type Query {
+	me: User
+}`,
+		"shared.gql": `@go(options: {package: "app"})
+
+type User {
+	id: ID!
+}`,
+		"other.gql": `@go(options: {package: "other"})
+
+type Other {
+	id: ID!
+}`,
+	}
+
+	var docs []*ast.Document
+	for _, name := range []string{"schema.gql", "user.gql", "shared.gql", "other.gql"} {
+		doc, err := parser.ParseDoc(token.NewDocSet(), name, strings.NewReader(srcs[name]), 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		docs = append(docs, doc)
+	}
+
+	g := &Generator{}
+
+	t.Run("Packages", func(subT *testing.T) {
+		files := make(testFiles)
+		err := g.GeneratePackage(compiler.WithContext(context.Background(), files), docs, "")
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		if len(files) != 2 || files["app.go"] == nil || files["other.go"] == nil {
+			subT.Fatalf("expected app.go and other.go but got: %v", files)
+		}
+
+		app := files["app.go"].String()
+		if n := strings.Count(app, "var UserType"); n != 1 {
+			subT.Fatalf("expected a single UserType but got %d in:\n%s", n, app)
+		}
+		if n := strings.Count(app, "package app"); n != 1 {
+			subT.Fatalf("expected a single package clause but got %d in:\n%s", n, app)
+		}
+		for _, ex := range []string{"// source: schema.gql, user.gql, shared.gql\n", "\"me\": &graphql.Field{", "Query: QueryType,"} {
+			if !strings.Contains(app, ex) {
+				subT.Fatalf("expected %s in:\n%s", ex, app)
+			}
+		}
+		if !strings.Contains(files["other.go"].String(), "func RegisterTypes(") {
+			subT.Fatalf("expected a type library but got:\n%s", files["other.go"])
+		}
+	})
+
+	t.Run("MultipleSchemas", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "schema2.gql", strings.NewReader(srcs["schema.gql"]), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		err = g.GeneratePackage(compiler.WithContext(context.Background(), make(testFiles)), []*ast.Document{docs[0], doc}, "")
+		if _, ok := err.(compiler.GeneratorError); !ok {
+			subT.Fatalf("expected a generator error but got: %v", err)
+		}
+	})
+
+	t.Run("ConflictingTypes", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "user2.gql", strings.NewReader(`@go(options: {package: "app"})
+
+type User {
+	id: ID!
+	name: String
+}`), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		err = g.GeneratePackage(compiler.WithContext(context.Background(), make(testFiles)), []*ast.Document{docs[0], docs[1], doc}, "")
+		if err == nil || !strings.Contains(err.Error(), "conflicting declarations of User in user.gql and user2.gql") {
+			subT.Fatalf("expected conflicting declarations but got: %v", err)
+		}
+	})
+
+	t.Run("ConflictingOptions", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "models.gql", strings.NewReader(`@go(options: {package: "app", models: true})
+
+type Post {
+	id: ID!
+}`), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		err = g.GeneratePackage(compiler.WithContext(context.Background(), make(testFiles)), []*ast.Document{docs[0], doc}, "")
+		if err == nil || !strings.Contains(err.Error(), "options of package app conflict with those of schema.gql") {
+			subT.Fatalf("expected conflicting options but got: %v", err)
+		}
+	})
+}
+
+func TestGenerator_GenerateLayout(t *testing.T) {
+	gqlSrc := `schema {
+	query: Query
+}
+
+type Query {
+	user(filter: UserFilter): User
+}
+
+type User {
+	id: ID!
+	role: Role
+}
+
+enum Role {
+	ADMIN
+}
+
+input UserFilter {
+	role: Role
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "layout.gql", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	testCases := []struct {
+		Name string
+		Ex   []string
+	}{
+		{
+			Name: "single",
+			Ex:   []string{"layout.go"},
+		},
+		{
+			Name: "per-type",
+			Ex:   []string{"layout.go", "query.gen.go", "user.gen.go", "role.gen.go", "user_filter.gen.go"},
+		},
+		{
+			Name: "per-kind",
+			Ex:   []string{"layout.go", "objects.gen.go", "enums.gen.go", "inputs.gen.go"},
+		},
+	}
+
+	g := &Generator{}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			files := make(testFiles)
+			err := g.Generate(compiler.WithContext(context.Background(), files), doc, `{"resolvers": true, "layout": "`+testCase.Name+`"}`)
+			if err != nil {
+				subT.Error(err)
+				return
+			}
+
+			if len(files) != len(testCase.Ex) {
+				subT.Fatalf("expected files %v but got: %v", testCase.Ex, files)
+			}
+			for _, name := range testCase.Ex {
+				if files[name] == nil {
+					subT.Fatalf("expected file %s but got: %v", name, files)
+				}
+			}
+		})
+	}
+
+	t.Run("PerType", func(subT *testing.T) {
+		files := make(testFiles)
+		err := g.Generate(compiler.WithContext(context.Background(), files), doc, `{"resolvers": true, "layout": "per-type"}`)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		user := files["user.gen.go"].String()
+		for _, ex := range []string{"var UserType = ", "type User struct {", "type UserResolver interface {", `"context"`} {
+			if !strings.Contains(user, ex) {
+				subT.Fatalf("expected %s in:\n%s", ex, user)
+			}
+		}
+		if !strings.Contains(files["layout.go"].String(), "Schema, err = graphql.NewSchema(") {
+			subT.Fatalf("expected the schema in:\n%s", files["layout.go"])
+		}
+		if !strings.Contains(files["user_filter.gen.go"].String(), "func decodeUserFilter(") {
+			subT.Fatalf("expected the decoder in:\n%s", files["user_filter.gen.go"])
+		}
+	})
+
+	t.Run("Unknown", func(subT *testing.T) {
+		err := g.Generate(compiler.WithContext(context.Background(), make(testFiles)), doc, `{"layout": "per-field"}`)
+		if _, ok := err.(compiler.GeneratorError); !ok {
+			subT.Fatalf("expected a generator error but got: %v", err)
+		}
+	})
+}
+
+func TestSnakeCase(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Ex   string
+	}{
+		{Name: "User", Ex: "user"},
+		{Name: "UserFilter", Ex: "user_filter"},
+		{Name: "URLInput", Ex: "url_input"},
+		{Name: "Point3D", Ex: "point3d"},
+		{Name: "user_id", Ex: "user_id"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			if name := snakeCase(testCase.Name); name != testCase.Ex {
+				subT.Fatalf("expected %s but got: %s", testCase.Ex, name)
+			}
+		})
+	}
+}
+
+func TestPrintSDL(t *testing.T) {
+	gqlSrc := `schema {query: Query}
+
+"A directive"
+directive @tag(name: String = "a", values: [Int] = [1, 2]) on FIELD_DEFINITION | OBJECT
+
+scalar Time
+
+type Query implements Node @tag(name: "q") {
+	"""
+	The node with the given id.
+	"""
+	node(
+		"The id of the node"
+		id: ID!
+	): Node
+	nodes(first: Int = 10, filter: Filter = {text: "x"}): [Node!]! @deprecated
+}
+
+interface Node {
+	id: ID!
+}
+
+union Result = Query | Time
+
+enum Direction {
+	NORTH
+	SOUTH @deprecated(reason: "gone")
+}
+
+input Filter {
+	text: String
+	direction: Direction = NORTH
+}`
+
+	ex := `schema {
+	query: Query
+}
+
+"A directive"
+directive @tag(name: String = "a", values: [Int] = [1, 2]) on FIELD_DEFINITION | OBJECT
+
+scalar Time
+
+type Query implements Node @tag(name: "q") {
+	"The node with the given id."
+	node(
+		"The id of the node"
+		id: ID!
+	): Node
+	nodes(first: Int = 10, filter: Filter = {text: "x"}): [Node!]! @deprecated
+}
+
+interface Node {
+	id: ID!
+}
+
+union Result = Query | Time
+
+enum Direction {
+	NORTH
+	SOUTH @deprecated(reason: "gone")
+}
+
+input Filter {
+	text: String
+	direction: Direction = NORTH
+}
+`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "sdl", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sdl := printSDL(doc.Schema, doc.Types, true)
+	compareBytes(t, []byte(ex), []byte(sdl))
+
+	// The printed SDL must be parsed into the same SDL again
+	doc, err = parser.ParseDoc(token.NewDocSet(), "sdl", strings.NewReader(sdl), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	compareBytes(t, []byte(sdl), []byte(printSDL(doc.Schema, doc.Types, true)))
+
+	// Canonical SDL must be printed as it was parsed
+	testCases := []struct {
+		Name string
+		SDL  string
+	}{
+		{
+			Name: "RootOps",
+			SDL: `schema @tag {
+	query: Query
+	mutation: Mutation
+	subscription: Subscription
+}
+
+type Query {
+	a: Int
+}
+
+type Mutation {
+	a: Int
+}
+
+type Subscription {
+	a: Int
+}
+`,
+		},
+		{
+			Name: "Types",
+			SDL: `scalar Time @tag(name: "t")
+
+type User implements Node & Named @key(fields: "id") {
+	id: ID!
+	names(first: Int = 1, sort: [[Direction!]!] = [[NORTH]]): [[String!]]!
+	at(
+		"When"
+		zone: String @tag
+	): Time
+}
+
+interface Named {
+	name: String
+}
+
+union Result @tag = User | Time
+
+enum Direction {
+	NORTH @tag
+	SOUTH
+}
+
+input Filter @tag {
+	point: Point = {x: 1.5, y: -2, tags: ["a", "b"], nested: {enabled: true, disabled: null}}
+	after: Time
+}
+
+input Empty
+
+type Marker
+`,
+		},
+		{
+			Name: "Descriptions",
+			SDL: `"""
+Contains back\slashes,
+over lines.
+"""
+type Query {
+	"One line"
+	a: Int
+	"""
+	Two
+
+	lines
+	"""
+	b: Int
+}
+
+"Locations"
+directive @key(fields: String!) on OBJECT | INTERFACE | FIELD_DEFINITION | ARGUMENT_DEFINITION
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			doc, err := parser.ParseDoc(token.NewDocSet(), "sdl", strings.NewReader(testCase.SDL), 0)
+			if err != nil {
+				subT.Error(err)
+				return
+			}
+
+			compareBytes(subT, []byte(testCase.SDL), []byte(printSDL(doc.Schema, doc.Types, true)))
+		})
+	}
+}
+
+func TestFileHeader(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Version string
+		Hash    string
+		Ex      string
+	}{
+		{
+			Name: "Source",
+			Ex: `// Code generated by gqlc-go. DO NOT EDIT.
+// source: test.gql
+
+`,
+		},
+		{
+			Name:    "VersionAndHash",
+			Version: "v1.0.0",
+			Hash:    schemaHash("scalar Time\n"),
+			Ex: `// Code generated by gqlc-go. DO NOT EDIT.
+// source: test.gql
+// version: v1.0.0
+// schema: sha256:` + fmt.Sprintf("%x", sha256.Sum256([]byte("scalar Time\n"))) + `
+
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(subT *testing.T) {
+			compareBytes(subT, []byte(testCase.Ex), []byte(fileHeader("test.gql", testCase.Version, testCase.Hash)))
+		})
+	}
+}
+
+func TestGenerator_GenerateSchemaHash(t *testing.T) {
+	hash := func(gqlSrc string) string {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "hash", strings.NewReader(gqlSrc), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b bytes.Buffer
+		g := &Generator{}
+		err = g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &b}), doc, `{"schemaHash": true}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line := strings.Split(b.String(), "\n")[2]
+		if !strings.HasPrefix(line, "// schema: sha256:") {
+			t.Fatalf("expected a schema hash but got: %s", b.String())
+		}
+		return line
+	}
+
+	a, b := hash("type Query { hello: String }"), hash("type Query {\n\thello: String\n}\n")
+	if a != b {
+		t.Fatalf("expected formatting to be ignored, but got: %s and %s", a, b)
+	}
+	if c := hash("type Query { hello: Int }"); a == c {
+		t.Fatalf("expected the hash to change with the schema, but got: %s", c)
+	}
+}
+
+func TestGenerator_GenerateGophers(t *testing.T) {
+	gqlSrc := `scalar JSON
+
+enum Role {
+	ADMIN
+	USER
+}
+
+input UserFilter {
+	role: Role = USER
+	names: [String!]
+}
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	name: String
+	meta: JSON
+}
+
+type Query {
+	users(filter: UserFilter!, first: Int = 10): [User!]!
+	node(id: ID!): Node
+}
+
+type Subscription {
+	userAdded: User!
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "gophers", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g := &Generator{}
+
+	t.Run("Resolvers", func(subT *testing.T) {
+		var b bytes.Buffer
+		err := g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &b}), doc, `{"backend": "graph-gophers"}`)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		exs := []string{
+			"\t\"github.com/graph-gophers/graphql-go\"\n",
+			"const Schema = `schema {\n\tquery: Query\n\tsubscription: Subscription\n}\n\nscalar JSON\n",
+			`// RootResolver resolves the root operation types of the schema.
+type RootResolver interface {
+	QueryResolver
+	SubscriptionResolver
+}
+`,
+			`type UserFilter struct {
+	Role  Role
+	Names *[]string
+}
+`,
+			`type NodeResolver interface {
+	ID(ctx context.Context) (graphql.ID, error)
+	ToUser() (UserResolver, bool)
+}
+`,
+			`type UserResolver interface {
+	ID(ctx context.Context) (graphql.ID, error)
+	Name(ctx context.Context) (*string, error)
+	Meta(ctx context.Context) (*JSON, error)
+}
+`,
+			`type QueryUsersArgs struct {
+	Filter UserFilter
+	First  int32
+}
+`,
+			"	Users(ctx context.Context, args QueryUsersArgs) ([]UserResolver, error)\n",
+			"	UserAdded(ctx context.Context) (<-chan UserResolver, error)\n",
+		}
+		for _, ex := range exs {
+			if !strings.Contains(b.String(), ex) {
+				subT.Fatalf("expected:\n%s\nbut got:\n%s", ex, b.String())
+			}
+		}
+	})
+
+	t.Run("SharedRootField", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "gophers", strings.NewReader(`type Query {
+	node: Int
+}
+
+type Mutation {
+	node: Int
+}`), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		err = g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &bytes.Buffer{}}), doc, `{"backend": "graph-gophers"}`)
+		if err == nil || !strings.Contains(err.Error(), "Query.node and Mutation.node are both resolved by RootResolver.Node") {
+			subT.Fatalf("expected a shared root field error, but got: %v", err)
+		}
+	})
+
+	t.Run("UnknownBackend", func(subT *testing.T) {
+		err := g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &bytes.Buffer{}}), doc, `{"backend": "graphql"}`)
+		if err == nil || !strings.Contains(err.Error(), "unknown backend: graphql") {
+			subT.Fatalf("expected an unknown backend error, but got: %v", err)
+		}
+	})
+
+	t.Run("Libraries", func(subT *testing.T) {
+		err := g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &bytes.Buffer{}}), doc, `{"backend": "graph-gophers", "libraries": {"Node": "example.com/node"}}`)
+		if err == nil || !strings.Contains(err.Error(), "aren't supported") {
+			subT.Fatalf("expected type libraries to be unsupported, but got: %v", err)
+		}
+	})
+}
+
+func TestGenerator_GenerateGqlgen(t *testing.T) {
+	gqlSrc := `enum Role {
+	ADMIN
+	USER
+}
+
+scalar Version
+
+input NewUser {
+	name: String!
+	role: Role
+}
+
+type User {
+	id: ID!
+	name: String!
+	friends(first: Int = 10): [User!]
+	version: Version
+}
+
+type Query {
+	users: [User!]!
+}
+
+type Mutation {
+	createUser(input: NewUser!, type: String): User!
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "gqlgen", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var b bytes.Buffer
+	g := &Generator{}
+	err = g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &b}), doc, `{"backend": "gqlgen"}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	exs := []string{
+		`type ResolverRoot interface {
+	User() UserResolver
+	Query() QueryResolver
+	Mutation() MutationResolver
+}
+`,
+		`// IsValid reports whether e is a value of Role.
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleAdmin, RoleUser:
+		return true
+	}
+	return false
+}
+`,
+		`func (e *Role) UnmarshalGQL(v interface{}) error {`,
+		`func (e Role) MarshalGQL(w io.Writer) {`,
+		`type Version struct {
+	Value interface{}
+}
+
+// UnmarshalGQL implements graphql.Unmarshaler.
+func (s *Version) UnmarshalGQL(v interface{}) error {
+	s.Value = v
+	return nil
+}
+
+// MarshalGQL implements graphql.Marshaler.
+func (s Version) MarshalGQL(w io.Writer) {
+	b, err := json.Marshal(s.Value)
+	if err != nil {
+		b = []byte("null")
+	}
+	w.Write(b)
+}
+`,
+		`type User struct {
+	ID      string   ` + "`json:\"id\"`" + `
+	Name    string   ` + "`json:\"name\"`" + `
+	Friends []*User  ` + "`json:\"friends\"`" + `
+	Version *Version ` + "`json:\"version\"`" + `
+}
+`,
+		`type UserResolver interface {
+	Friends(ctx context.Context, obj *User, first *int) ([]*User, error)
+}
+`,
+		`type QueryResolver interface {
+	Users(ctx context.Context) ([]*User, error)
+}
+`,
+		`type MutationResolver interface {
+	CreateUser(ctx context.Context, input NewUser, typeArg *string) (*User, error)
+}
+`,
+	}
+	for _, ex := range exs {
+		if !strings.Contains(b.String(), ex) {
+			t.Fatalf("expected:\n%s\nbut got:\n%s", ex, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "type Query struct") {
+		t.Fatalf("expected no models of root operation types, but got:\n%s", b.String())
+	}
+}
+
+func TestGenerator_GenerateExecutor(t *testing.T) {
+	gqlSrc := `scalar Time
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	name: String
+	friends(first: Int = 10): [User!]
+}
+
+type Query {
+	node(id: ID!): Node
+}
+
+type Subscription {
+	userAdded: User!
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "executor", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var b bytes.Buffer
+	g := &Generator{}
+	err = g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &b}), doc, `{"backend": "executor"}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	exs := []string{
+		`var TimeType = exec.Scalar{
+	Name:       "Time",
+	Serialize:  func(value interface{}) interface{} { return value },
+	ParseValue: func(value interface{}) interface{} { return value },
+}
+`,
+		`var schema = exec.NewSchema(exec.Schema{
+	Query:        "Query",
+	Subscription: "Subscription",
+	Types: []*exec.TypeDef{
+`,
+		`		{
+			Kind:       exec.KindObject,
+			Name:       "User",
+			Interfaces: []string{"Node"},
+			Fields: []*exec.FieldDef{
+				{
+					Name: "id",
+					Type: exec.MustParseType("ID!"),
+				},
+				{
+					Name: "name",
+					Type: exec.MustParseType("String"),
+				},
+				{
+					Name: "friends",
+					Args: []*exec.InputValueDef{
+						{
+							Name:         "first",
+							Type:         exec.MustParseType("Int"),
+							DefaultValue: "10",
+						},
+					},
+					Type: exec.MustParseType("[User!]"),
+				},
+			},
+		},
+`,
+		`			PossibleTypes: []string{"User"},
+`,
+		`		case "__typename":
+			res.Set(f.Alias, "Query")
+		case "__schema", "__type":
+			res.Set(f.Alias, e.Introspect(f))
+		case "node":
+`,
+		`func Execute(ctx context.Context, query string, vars map[string]interface{}) *exec.Response {`,
+		`func Subscribe(ctx context.Context, query string, vars map[string]interface{}) (<-chan *exec.Response, error) {`,
+		`		case "id":
+			v, ok := e.execUserID(ctx, f, obj)
+			if !ok {
+				return nil, false
+			}
+			res.Set(f.Alias, v)
+		case "name":
+			v, _ := e.execUserName(ctx, f, obj)
+			res.Set(f.Alias, v)
+`,
+		`func (e *executor) execQueryNode(ctx context.Context, f *exec.Field) (interface{}, bool) {
+	if QueryResolvers == nil {
+		e.Errorf(f, "QueryResolvers is nil")
+		return nil, false
+	}
+	args, err := decodeQueryNodeArgs(f.Args)
+	if err != nil {
+		e.Error(f, err)
+		return nil, false
+	}
+	v, err := QueryResolvers.Node(ctx, args)
+	if err != nil {
+		e.Error(f, err)
+		return nil, false
+	}
+	return e.completeNode(ctx, f, v)
+}
+`,
+		`func (e *executor) completeNode(ctx context.Context, f *exec.Field, v Node) (interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	switch v := v.(type) {
+	case *User:
+		return e.completeUser(ctx, f, v)
+	}
+`,
+		`func (e *executor) completeNonNullUser(ctx context.Context, f *exec.Field, v *User) (interface{}, bool) {
+	if v == nil {
+		e.Errorf(f, "%s: null value for type User!", f.Name)
+		return nil, false
+	}
+	return e.execUser(ctx, f.Selections, v)
+}
+`,
+	}
+	for _, ex := range exs {
+		if !strings.Contains(b.String(), ex) {
+			t.Fatalf("expected:\n%s\nbut got:\n%s", ex, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "ParseLiteral") {
+		t.Fatalf("expected no graphql-go scalar funcs, but got:\n%s", b.String())
+	}
+}
+
+// kindBackend emits a const with the kind of every type.
+type kindBackend struct{}
+
+func (kindBackend) Header(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	g.Import("fmt")
+	g.P("// Package ", g.Options().Package, " has ", len(types), " types.")
+	return nil
+}
+
+func (kindBackend) Schema(g *Generator, schema *ast.TypeDecl) error {
+	g.P("const Query = \"", rootOpType(schema, "query"), "\"")
+	return nil
+}
+
+func (kindBackend) kind(g *Generator, ts *ast.TypeSpec, kind string) error {
+	if ts.Name.Name == "Fail" {
+		return fmt.Errorf("can't emit %s", ts.Name.Name)
+	}
+	g.P("const ", ts.Name.Name, "Kind = \"", kind, "\"")
+	return nil
+}
+
+func (b kindBackend) Scalar(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "scalar")
+}
+
+func (b kindBackend) Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "object")
+}
+
+func (b kindBackend) Interface(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "interface")
+}
+
+func (b kindBackend) Union(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "union")
+}
+
+func (b kindBackend) Enum(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "enum")
+}
+
+func (b kindBackend) Input(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "input")
+}
+
+func (b kindBackend) Directive(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "directive")
+}
+
+func (kindBackend) Footer(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	g.P()
+	g.P("func init() { fmt.Println(Query) }")
+	return nil
+}
+
+// countBackend counts the types it emits.
+type countBackend struct {
+	BaseBackend
+	types int
+}
+
+func (b *countBackend) kind(g *Generator, ts *ast.TypeSpec, kind string) error {
+	b.types++
+	return nil
+}
+
+func (b *countBackend) Scalar(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "scalar")
+}
+
+func (b *countBackend) Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "object")
+}
+
+func (b *countBackend) Enum(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	return b.kind(g, ts, "enum")
+}
+
+func TestRegisterBackend(t *testing.T) {
+	RegisterBackend("kinds", func() Backend { return kindBackend{} })
+
+	gqlSrc := `scalar Time
+
+enum Role {
+	ADMIN
+}
+
+type Query {
+	now: Time
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "kinds", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var b bytes.Buffer
+	g := &Generator{}
+	err = g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: &b}), doc, `{"package": "kinds", "backend": "kinds"}`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ex := `package kinds
+
+import "fmt"
+
+// Package kinds has 3 types.
+const Query = "Query"
+const TimeKind = "scalar"
+const RoleKind = "enum"
+const QueryKind = "object"
+
+func init() { fmt.Println(Query) }
+`
+	if !strings.HasSuffix(b.String(), ex) {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", ex, b.String())
+	}
+
+	t.Run("Error", func(subT *testing.T) {
+		doc, err := parser.ParseDoc(token.NewDocSet(), "kinds", strings.NewReader("type Fail {\n\tid: ID\n}"), 0)
+		if err != nil {
+			subT.Error(err)
+			return
+		}
+
+		err = g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: ioutil.Discard}), doc, `{"backend": "kinds"}`)
+		if err == nil || !strings.Contains(err.Error(), "can't emit Fail") {
+			subT.Fatalf("expected backend error but got: %v", err)
+		}
+	})
+
+	t.Run("PerDocument", func(subT *testing.T) {
+		var created []*countBackend
+		RegisterBackend("count", func() Backend {
+			b := &countBackend{}
+			created = append(created, b)
+			return b
+		})
+
+		for i := 0; i < 2; i++ {
+			err := g.Generate(compiler.WithContext(context.Background(), testCtx{Writer: ioutil.Discard}), doc, `{"backend": "count"}`)
+			if err != nil {
+				subT.Error(err)
+				return
+			}
+		}
+		if len(created) != 2 || created[0].types != 3 || created[1].types != 3 {
+			subT.Fatalf("expected a backend per document, which emitted 3 types, but got %d", len(created))
+		}
+	})
+
+	t.Run("Twice", func(subT *testing.T) {
+		defer func() {
+			if recover() == nil {
+				subT.Fatal("expected registering a backend twice to panic")
+			}
+		}()
+		RegisterBackend("kinds", func() Backend { return kindBackend{} })
+	})
+
+	t.Run("Builtin", func(subT *testing.T) {
+		defer func() {
+			if recover() == nil {
+				subT.Fatal("expected registering a builtin backend to panic")
+			}
+		}()
+		RegisterBackend(backendGqlgen, func() Backend { return kindBackend{} })
+	})
+}
+
+func BenchmarkGenerator_Generate(b *testing.B) {
+	g := &Generator{}
+
+	var buf bytes.Buffer
+	ctx := compiler.WithContext(context.Background(), testCtx{Writer: &buf})
+
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+
+		err := g.Generate(ctx, testDoc, "")
+		if err != nil {
+			b.Error(err)
+			return
+		}
+	}
+}
+
+func ExampleGenerator_Generate() {
+	g := new(Generator)
+
+	gqlSrc := `schema {
+	query: Query
+}
+
+"Query represents the queries this example provides."
+type Query {
+	hello: String
+}`
+
+	doc, err := parser.ParseDoc(token.NewDocSet(), "example", strings.NewReader(gqlSrc), 0)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	var b bytes.Buffer
+	ctx := compiler.WithContext(context.Background(), &testCtx{Writer: &b}) // Pass in an actual
+	err = g.Generate(ctx, doc, `{"descriptions": true}`)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	fmt.Println(b.String())
+
+	// Output:
+	// // Code generated by gqlc-go. DO NOT EDIT.
+	// // source: example
+	//
+	// package main
+	//
+	// import "github.com/graphql-go/graphql"
+	//
+	// var Schema graphql.Schema
+	//
+	// var QueryType = graphql.NewObject(graphql.ObjectConfig{
+	// 	Name: "Query",
+	//	Fields: graphql.Fields{
+	//		"hello": &graphql.Field{
+	//			Type:    graphql.String,
+	//			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return nil, nil }, // TODO
+	//		},
+	//	},
+	//	Description: "Query represents the queries this example provides.",
+	// })
+	//
+	// func init() {
+	//	var err error
+	//	Schema, err = graphql.NewSchema(graphql.SchemaConfig{
+	//		Query: QueryType,
+	//		Types: []graphql.Type{
+	//			QueryType,
+	//		},
 	//	})
 	//	if err != nil {
 	//		panic(err)
@@ -0,0 +1,822 @@
+package golang
+
+import (
+	goast "go/ast"
+	"go/printer"
+	gotoken "go/token"
+	"strconv"
+	"strings"
+)
+
+// builder constructs Go code as syntax trees, which are printed by go/printer, so
+// the generated code is syntactically valid by construction. Every declaration the
+// backends, models, resolver interfaces and decoders generate is built this way.
+type builder struct {
+	// multiline contains the composite literals which have an element per line
+	multiline map[*goast.CompositeLit]bool
+
+	// oneline contains the blocks, e.g. of short funcs, which are on a single line
+	oneline map[*goast.BlockStmt]bool
+
+	// comments maps nodes to the line comments which follow them
+	comments map[goast.Node]string
+
+	// gaps contains the statements which are preceded by an empty line
+	gaps map[goast.Stmt]bool
+}
+
+func newBuilder() *builder {
+	return &builder{
+		multiline: make(map[*goast.CompositeLit]bool),
+		oneline:   make(map[*goast.BlockStmt]bool),
+		comments:  make(map[goast.Node]string),
+		gaps:      make(map[goast.Stmt]bool),
+	}
+}
+
+// name returns an identifier, or a selector for qualified names e.g. graphql.Int.
+func (b *builder) name(name string) goast.Expr {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return &goast.SelectorExpr{X: b.name(name[:i]), Sel: goast.NewIdent(name[i+1:])}
+	}
+	return goast.NewIdent(name)
+}
+
+// typ returns a type, as it's written in Go e.g. by goType.
+func (b *builder) typ(typ string) goast.Expr {
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		return b.star(b.typ(typ[1:]))
+	case strings.HasPrefix(typ, "[]"):
+		return &goast.ArrayType{Elt: b.typ(typ[2:])}
+	case strings.HasPrefix(typ, "<-chan "):
+		return &goast.ChanType{Dir: goast.RECV, Value: b.typ(typ[len("<-chan "):])}
+	case typ == "interface{}":
+		return anyType()
+	}
+	return b.name(typ)
+}
+
+// str returns a string literal of s.
+func (b *builder) str(s string) goast.Expr {
+	return &goast.BasicLit{Kind: gotoken.STRING, Value: strconv.Quote(s)}
+}
+
+// lit returns a literal, which is given as it's written in Go.
+func (b *builder) lit(kind gotoken.Token, value string) goast.Expr {
+	return &goast.BasicLit{Kind: kind, Value: value}
+}
+
+// call returns a call of the func with the given name.
+func (b *builder) call(fn string, args ...goast.Expr) *goast.CallExpr {
+	return &goast.CallExpr{Fun: b.name(fn), Args: args}
+}
+
+// block returns a composite literal with an element per line.
+func (b *builder) block(typ goast.Expr, elts ...goast.Expr) *goast.CompositeLit {
+	lit := &goast.CompositeLit{Type: typ, Elts: elts}
+	b.multiline[lit] = true
+	return lit
+}
+
+// inline returns a composite literal on a single line.
+func (b *builder) inline(typ goast.Expr, elts ...goast.Expr) *goast.CompositeLit {
+	return &goast.CompositeLit{Type: typ, Elts: elts}
+}
+
+// kv returns a key-value pair of a composite literal.
+func (b *builder) kv(key, value goast.Expr) goast.Expr {
+	return &goast.KeyValueExpr{Key: key, Value: value}
+}
+
+// field returns a field of a struct literal.
+func (b *builder) field(name string, value goast.Expr) goast.Expr {
+	return b.kv(goast.NewIdent(name), value)
+}
+
+// spread passes the last argument of a call as the variadic parameter.
+func (b *builder) spread(call *goast.CallExpr) *goast.CallExpr {
+	call.Ellipsis = 1
+	return call
+}
+
+// not returns the negation of x.
+func (b *builder) not(x goast.Expr) goast.Expr {
+	return &goast.UnaryExpr{Op: gotoken.NOT, X: x}
+}
+
+// addr returns the address of x.
+func (b *builder) addr(x goast.Expr) goast.Expr {
+	return &goast.UnaryExpr{Op: gotoken.AND, X: x}
+}
+
+// star returns *x, i.e. a pointer type or the value x points to.
+func (b *builder) star(x goast.Expr) goast.Expr {
+	return &goast.StarExpr{X: x}
+}
+
+// assert returns the type assertion x.(typ), or x.(type) if typ is nil.
+func (b *builder) assert(x, typ goast.Expr) goast.Expr {
+	return &goast.TypeAssertExpr{X: x, Type: typ}
+}
+
+// binary returns the binary expression x op y.
+func (b *builder) binary(x goast.Expr, op gotoken.Token, y goast.Expr) goast.Expr {
+	return &goast.BinaryExpr{X: x, Op: op, Y: y}
+}
+
+// index returns the index expression x[i].
+func (b *builder) index(x, i goast.Expr) goast.Expr {
+	return &goast.IndexExpr{X: x, Index: i}
+}
+
+// param returns a parameter of a func, or a receiver, which may be unnamed.
+func (b *builder) param(name string, typ goast.Expr) *goast.Field {
+	f := &goast.Field{Type: typ}
+	if name != "" {
+		f.Names = []*goast.Ident{goast.NewIdent(name)}
+	}
+	return f
+}
+
+// doc returns a comment of the given lines, or nil if there are none.
+func (b *builder) doc(lines ...string) *goast.CommentGroup {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	cg := &goast.CommentGroup{}
+	for _, line := range lines {
+		text := "//"
+		if line != "" {
+			text += " " + line
+		}
+		cg.List = append(cg.List, &goast.Comment{Text: text})
+	}
+	return cg
+}
+
+// member returns a field of a struct, or a method of an interface if typ is a func
+// type. Embedded types have no name.
+func (b *builder) member(doc *goast.CommentGroup, name string, typ goast.Expr) *goast.Field {
+	f := b.param(name, typ)
+	f.Doc = doc
+	return f
+}
+
+// jsonField returns a field of a struct, which is tagged with the name of its JSON key.
+func (b *builder) jsonField(doc *goast.CommentGroup, name string, typ goast.Expr, key string) *goast.Field {
+	f := b.member(doc, name, typ)
+	f.Tag = &goast.BasicLit{Kind: gotoken.STRING, Value: "`json:" + strconv.Quote(key) + "`"}
+	return f
+}
+
+// structType returns a struct type with a field per line.
+func (b *builder) structType(fields ...*goast.Field) goast.Expr {
+	return &goast.StructType{Fields: &goast.FieldList{List: fields}}
+}
+
+// interfaceType returns an interface type with a method per line.
+func (b *builder) interfaceType(methods ...*goast.Field) goast.Expr {
+	return &goast.InterfaceType{Methods: &goast.FieldList{List: methods}}
+}
+
+// funcType returns the type of a func with the given parameters and unnamed results.
+func (b *builder) funcType(params []*goast.Field, results ...goast.Expr) *goast.FuncType {
+	typ := &goast.FuncType{Params: &goast.FieldList{List: params}}
+	if len(results) > 0 {
+		typ.Results = &goast.FieldList{}
+		for _, res := range results {
+			typ.Results.List = append(typ.Results.List, &goast.Field{Type: res})
+		}
+	}
+	return typ
+}
+
+// funcLit returns a func literal with a statement per line.
+func (b *builder) funcLit(typ *goast.FuncType, body ...goast.Stmt) *goast.FuncLit {
+	return &goast.FuncLit{Type: typ, Body: &goast.BlockStmt{List: body}}
+}
+
+// shortFunc returns a func literal on a single line.
+func (b *builder) shortFunc(typ *goast.FuncType, body ...goast.Stmt) *goast.FuncLit {
+	lit := b.funcLit(typ, body...)
+	b.oneline[lit.Body] = true
+	return lit
+}
+
+// comment adds a line comment after an element of a composite literal.
+func (b *builder) comment(elt goast.Expr, text string) goast.Expr {
+	b.comments[elt] = "// " + text
+	return elt
+}
+
+// gap adds an empty line before a statement.
+func (b *builder) gap(s goast.Stmt) goast.Stmt {
+	b.gaps[s] = true
+	return s
+}
+
+// exprStmt returns the statement of an expression, e.g. a call.
+func (b *builder) exprStmt(x goast.Expr) goast.Stmt {
+	return &goast.ExprStmt{X: x}
+}
+
+// define returns the short variable declaration of the given, comma separated names.
+func (b *builder) define(names string, values ...goast.Expr) goast.Stmt {
+	s := &goast.AssignStmt{Tok: gotoken.DEFINE, Rhs: values}
+	for _, name := range strings.Split(names, ", ") {
+		s.Lhs = append(s.Lhs, goast.NewIdent(name))
+	}
+	return s
+}
+
+// assign returns the assignment of a value to x.
+func (b *builder) assign(x, value goast.Expr) goast.Stmt {
+	return &goast.AssignStmt{Lhs: []goast.Expr{x}, Tok: gotoken.ASSIGN, Rhs: []goast.Expr{value}}
+}
+
+// assignAll returns the assignment of the results of a call, or the values of a
+// type assertion, to the given expressions.
+func (b *builder) assignAll(lhs []goast.Expr, value goast.Expr) goast.Stmt {
+	return &goast.AssignStmt{Lhs: lhs, Tok: gotoken.ASSIGN, Rhs: []goast.Expr{value}}
+}
+
+// varStmt returns the declaration of a var with the zero value of its type, within a func.
+func (b *builder) varStmt(name string, typ goast.Expr) goast.Stmt {
+	return &goast.DeclStmt{Decl: b.zeroVar(name, typ)}
+}
+
+// typedVar returns the declaration of a var of the given type, within a func.
+func (b *builder) typedVar(name string, typ, value goast.Expr) goast.Stmt {
+	return &goast.DeclStmt{Decl: &goast.GenDecl{Tok: gotoken.VAR, Specs: []goast.Spec{&goast.ValueSpec{
+		Names:  []*goast.Ident{goast.NewIdent(name)},
+		Type:   typ,
+		Values: []goast.Expr{value},
+	}}}}
+}
+
+// ret returns a return statement.
+func (b *builder) ret(results ...goast.Expr) goast.Stmt {
+	return &goast.ReturnStmt{Results: results}
+}
+
+// ifStmt returns an if statement, whose init statement may be nil.
+func (b *builder) ifStmt(init goast.Stmt, cond goast.Expr, body ...goast.Stmt) goast.Stmt {
+	return &goast.IfStmt{Init: init, Cond: cond, Body: &goast.BlockStmt{List: body}}
+}
+
+// orElse adds the else branch of an if statement. A single if statement is chained
+// as else if, while any other statements are the block of the else branch.
+func (b *builder) orElse(s goast.Stmt, els ...goast.Stmt) goast.Stmt {
+	x := s.(*goast.IfStmt)
+	if next, ok := els[0].(*goast.IfStmt); ok && len(els) == 1 {
+		x.Else = next
+		return s
+	}
+	x.Else = &goast.BlockStmt{List: els}
+	return s
+}
+
+// ifErr returns the check of err, which returns the given results unless it's nil.
+func (b *builder) ifErr(results ...goast.Expr) goast.Stmt {
+	return b.ifStmt(nil, b.binary(b.name("err"), gotoken.NEQ, b.name("nil")), b.ret(results...))
+}
+
+// switchStmt returns an expression switch.
+func (b *builder) switchStmt(tag goast.Expr, clauses ...goast.Stmt) goast.Stmt {
+	return &goast.SwitchStmt{Tag: tag, Body: &goast.BlockStmt{List: clauses}}
+}
+
+// typeSwitch returns a switch over the type of x, which binds its value to the given name, if any.
+func (b *builder) typeSwitch(name string, x goast.Expr, clauses ...goast.Stmt) goast.Stmt {
+	var assign goast.Stmt = b.exprStmt(b.assert(x, nil))
+	if name != "" {
+		assign = b.define(name, b.assert(x, nil))
+	}
+	return &goast.TypeSwitchStmt{Assign: assign, Body: &goast.BlockStmt{List: clauses}}
+}
+
+// caseClause returns a case of a switch, or its default if there are no expressions.
+func (b *builder) caseClause(list []goast.Expr, body ...goast.Stmt) goast.Stmt {
+	return &goast.CaseClause{List: list, Body: body}
+}
+
+// forStmt returns a for statement without a condition, i.e. an endless loop.
+func (b *builder) forStmt(body ...goast.Stmt) goast.Stmt {
+	return &goast.ForStmt{Body: &goast.BlockStmt{List: body}}
+}
+
+// selectStmt returns a select statement.
+func (b *builder) selectStmt(clauses ...goast.Stmt) goast.Stmt {
+	return &goast.SelectStmt{Body: &goast.BlockStmt{List: clauses}}
+}
+
+// commClause returns a case of a select statement.
+func (b *builder) commClause(comm goast.Stmt, body ...goast.Stmt) goast.Stmt {
+	return &goast.CommClause{Comm: comm, Body: body}
+}
+
+// branch returns a branch statement, e.g. continue.
+func (b *builder) branch(tok gotoken.Token) goast.Stmt {
+	return &goast.BranchStmt{Tok: tok}
+}
+
+// recv returns the receive operation <-x.
+func (b *builder) recv(x goast.Expr) goast.Expr {
+	return &goast.UnaryExpr{Op: gotoken.ARROW, X: x}
+}
+
+// rangeStmt returns a for statement over the range of x, whose value may be omitted.
+func (b *builder) rangeStmt(key, value string, x goast.Expr, body ...goast.Stmt) goast.Stmt {
+	s := &goast.RangeStmt{Key: goast.NewIdent(key), Tok: gotoken.DEFINE, X: x, Body: &goast.BlockStmt{List: body}}
+	if value != "" {
+		s.Value = goast.NewIdent(value)
+	}
+	return s
+}
+
+// varDecl returns the declaration of a var.
+func (b *builder) varDecl(name string, value goast.Expr) goast.Decl {
+	return &goast.GenDecl{Tok: gotoken.VAR, Specs: []goast.Spec{&goast.ValueSpec{
+		Names:  []*goast.Ident{goast.NewIdent(name)},
+		Values: []goast.Expr{value},
+	}}}
+}
+
+// zeroVar returns the declaration of a var with the zero value of its type.
+func (b *builder) zeroVar(name string, typ goast.Expr) goast.Decl {
+	return &goast.GenDecl{Tok: gotoken.VAR, Specs: []goast.Spec{&goast.ValueSpec{
+		Names: []*goast.Ident{goast.NewIdent(name)},
+		Type:  typ,
+	}}}
+}
+
+// withDoc adds a doc comment to a declaration.
+func (b *builder) withDoc(doc *goast.CommentGroup, decl goast.Decl) goast.Decl {
+	switch x := decl.(type) {
+	case *goast.GenDecl:
+		x.Doc = doc
+	case *goast.FuncDecl:
+		x.Doc = doc
+	}
+	return decl
+}
+
+// typeDecl returns the declaration of a type.
+func (b *builder) typeDecl(doc *goast.CommentGroup, name string, typ goast.Expr) goast.Decl {
+	return &goast.GenDecl{Doc: doc, Tok: gotoken.TYPE, Specs: []goast.Spec{&goast.TypeSpec{
+		Name: goast.NewIdent(name),
+		Type: typ,
+	}}}
+}
+
+// constDecl returns the declaration of a single const.
+func (b *builder) constDecl(doc *goast.CommentGroup, name string, value goast.Expr) goast.Decl {
+	return &goast.GenDecl{Doc: doc, Tok: gotoken.CONST, Specs: []goast.Spec{b.constSpec(nil, name, nil, value)}}
+}
+
+// constBlock returns the declaration of the given consts in parentheses.
+func (b *builder) constBlock(specs ...goast.Spec) goast.Decl {
+	return &goast.GenDecl{Tok: gotoken.CONST, Lparen: 1, Specs: specs}
+}
+
+// constSpec returns a const of a const block, whose type may be nil.
+func (b *builder) constSpec(doc *goast.CommentGroup, name string, typ, value goast.Expr) goast.Spec {
+	return &goast.ValueSpec{
+		Doc:    doc,
+		Names:  []*goast.Ident{goast.NewIdent(name)},
+		Type:   typ,
+		Values: []goast.Expr{value},
+	}
+}
+
+// funcDecl returns the declaration of a func without parameters and results, e.g. init.
+func (b *builder) funcDecl(name string, body ...goast.Stmt) goast.Decl {
+	return &goast.FuncDecl{
+		Name: goast.NewIdent(name),
+		Type: b.funcType(nil),
+		Body: &goast.BlockStmt{List: body},
+	}
+}
+
+// docFunc returns the declaration of a func with a single line doc comment.
+func (b *builder) docFunc(doc, name string, typ *goast.FuncType, body ...goast.Stmt) goast.Decl {
+	return b.method(b.doc(doc), nil, name, typ, body...)
+}
+
+// method returns the declaration of a method, or a func if there's no receiver.
+func (b *builder) method(doc *goast.CommentGroup, recv *goast.Field, name string, typ *goast.FuncType, body ...goast.Stmt) *goast.FuncDecl {
+	fn := &goast.FuncDecl{
+		Doc:  doc,
+		Name: goast.NewIdent(name),
+		Type: typ,
+		Body: &goast.BlockStmt{List: body},
+	}
+	if recv != nil {
+		fn.Recv = &goast.FieldList{List: []*goast.Field{recv}}
+	}
+	return fn
+}
+
+// shortMethod returns the declaration of a method on a single line.
+func (b *builder) shortMethod(doc *goast.CommentGroup, recv *goast.Field, name string, typ *goast.FuncType, body ...goast.Stmt) goast.Decl {
+	fn := b.method(doc, recv, name, typ, body...)
+	b.oneline[fn.Body] = true
+	return fn
+}
+
+// printDecl prints a declaration built by b.
+func (g *Generator) printDecl(b *builder, decl goast.Decl) error {
+	l := &layout{builder: b, lines: []int{0}}
+	l.decl(decl)
+
+	fset := gotoken.NewFileSet()
+	fset.AddFile("", fset.Base(), l.off+1).SetLines(l.lines)
+
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if g.opts != nil && !g.opts.Format {
+		cfg.Mode |= printer.RawFormat
+	}
+	err := cfg.Fprint(g, fset, &printer.CommentedNode{Node: decl, Comments: l.groups})
+	if err != nil {
+		return err
+	}
+	g.WriteByte('\n')
+	return nil
+}
+
+// printDecls prints declarations built by b, each preceded by an empty line.
+func (g *Generator) printDecls(b *builder, decls ...goast.Decl) error {
+	for _, decl := range decls {
+		g.P()
+		if err := g.printDecl(b, decl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layout positions the nodes of a tree, as if it had been parsed from a source file
+// containing the lines of the generated code. go/printer then breaks lines where the
+// layout does.
+type layout struct {
+	*builder
+
+	// off is the offset of the next token, and lines the offsets at which the lines start
+	off   int
+	lines []int
+
+	// groups are the positioned comments
+	groups []*goast.CommentGroup
+}
+
+// tok returns the position of a token of n bytes.
+func (l *layout) tok(n int) gotoken.Pos {
+	pos := gotoken.Pos(1 + l.off)
+	l.off += n + 1
+	return pos
+}
+
+// text returns the position of text, which may span lines.
+func (l *layout) text(s string) gotoken.Pos {
+	pos := gotoken.Pos(1 + l.off)
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			l.lines = append(l.lines, l.off+i+1)
+		}
+	}
+	l.off += len(s) + 1
+	return pos
+}
+
+// nl starts a new line.
+func (l *layout) nl() {
+	l.off++
+	l.lines = append(l.lines, l.off)
+}
+
+// commentGroup positions a comment group, which is printed along with the tree.
+func (l *layout) commentGroup(cg *goast.CommentGroup) {
+	for _, c := range cg.List {
+		c.Slash = l.tok(len(c.Text))
+	}
+	l.groups = append(l.groups, cg)
+}
+
+// doc positions a doc comment on the lines before a node.
+func (l *layout) doc(cg *goast.CommentGroup) {
+	if cg == nil {
+		return
+	}
+	for _, c := range cg.List {
+		c.Slash = l.tok(len(c.Text))
+		l.nl()
+	}
+	l.groups = append(l.groups, cg)
+}
+
+func (l *layout) decl(d goast.Decl) {
+	switch x := d.(type) {
+	case *goast.GenDecl:
+		l.doc(x.Doc)
+		x.TokPos = l.tok(len(x.Tok.String()))
+		if x.Lparen.IsValid() {
+			x.Lparen = l.tok(1)
+		}
+		for _, spec := range x.Specs {
+			if x.Lparen.IsValid() {
+				l.nl()
+			}
+			l.spec(spec)
+		}
+		if x.Lparen.IsValid() {
+			l.nl()
+			x.Rparen = l.tok(1)
+		}
+	case *goast.FuncDecl:
+		l.doc(x.Doc)
+		x.Type.Func = l.tok(4)
+		if x.Recv != nil {
+			l.fields(x.Recv)
+		}
+		l.expr(x.Name)
+		l.signature(x.Type)
+		l.block(x.Body)
+	}
+}
+
+func (l *layout) spec(s goast.Spec) {
+	switch x := s.(type) {
+	case *goast.ValueSpec:
+		l.doc(x.Doc)
+		for _, name := range x.Names {
+			l.expr(name)
+		}
+		if x.Type != nil {
+			l.expr(x.Type)
+		}
+		if len(x.Values) > 0 {
+			l.tok(1)
+		}
+		for _, v := range x.Values {
+			l.expr(v)
+		}
+	case *goast.TypeSpec:
+		l.expr(x.Name)
+		l.expr(x.Type)
+	}
+}
+
+// signature positions the parameters and results of a func.
+func (l *layout) signature(typ *goast.FuncType) {
+	l.fields(typ.Params)
+	if typ.Results != nil && len(typ.Results.List) > 1 {
+		l.fields(typ.Results)
+	} else if typ.Results != nil {
+		l.expr(typ.Results.List[0].Type)
+	}
+}
+
+// fields positions a parenthesized list of fields, e.g. the parameters of a func.
+func (l *layout) fields(list *goast.FieldList) {
+	list.Opening = l.tok(1)
+	for _, f := range list.List {
+		for _, name := range f.Names {
+			l.expr(name)
+		}
+		l.expr(f.Type)
+	}
+	list.Closing = l.tok(1)
+}
+
+// members positions the fields of a struct, or the methods of an interface, each on a new line.
+func (l *layout) members(list *goast.FieldList) {
+	list.Opening = l.tok(1)
+	if len(list.List) == 0 {
+		list.Closing = l.tok(1)
+		return
+	}
+
+	for _, f := range list.List {
+		l.nl()
+		l.doc(f.Doc)
+		for _, name := range f.Names {
+			l.expr(name)
+		}
+		if typ, ok := f.Type.(*goast.FuncType); ok {
+			l.signature(typ)
+		} else {
+			l.expr(f.Type)
+		}
+		if f.Tag != nil {
+			l.expr(f.Tag)
+		}
+	}
+	l.nl()
+	list.Closing = l.tok(1)
+}
+
+func (l *layout) block(s *goast.BlockStmt) {
+	s.Lbrace = l.tok(1)
+	if l.oneline[s] {
+		for _, stmt := range s.List {
+			l.stmt(stmt)
+		}
+		s.Rbrace = l.tok(1)
+		return
+	}
+
+	l.stmts(s.List)
+	l.nl()
+	s.Rbrace = l.tok(1)
+}
+
+// stmts positions a list of statements, each on a new line.
+func (l *layout) stmts(list []goast.Stmt) {
+	for _, stmt := range list {
+		if l.gaps[stmt] {
+			l.nl()
+		}
+		l.nl()
+		l.stmt(stmt)
+	}
+}
+
+func (l *layout) stmt(s goast.Stmt) {
+	switch x := s.(type) {
+	case *goast.ExprStmt:
+		l.expr(x.X)
+	case *goast.AssignStmt:
+		for _, lhs := range x.Lhs {
+			l.expr(lhs)
+		}
+		x.TokPos = l.tok(len(x.Tok.String()))
+		for _, rhs := range x.Rhs {
+			l.expr(rhs)
+		}
+	case *goast.DeclStmt:
+		l.decl(x.Decl)
+	case *goast.ReturnStmt:
+		x.Return = l.tok(6)
+		for _, res := range x.Results {
+			l.expr(res)
+		}
+	case *goast.IfStmt:
+		x.If = l.tok(2)
+		if x.Init != nil {
+			l.stmt(x.Init)
+		}
+		l.expr(x.Cond)
+		l.block(x.Body)
+		if x.Else != nil {
+			l.tok(4)
+			l.stmt(x.Else)
+		}
+	case *goast.BlockStmt:
+		l.block(x)
+	case *goast.BranchStmt:
+		x.TokPos = l.tok(len(x.Tok.String()))
+	case *goast.GoStmt:
+		x.Go = l.tok(2)
+		l.expr(x.Call)
+	case *goast.DeferStmt:
+		x.Defer = l.tok(5)
+		l.expr(x.Call)
+	case *goast.ForStmt:
+		x.For = l.tok(3)
+		l.block(x.Body)
+	case *goast.RangeStmt:
+		x.For = l.tok(3)
+		l.expr(x.Key)
+		if x.Value != nil {
+			l.expr(x.Value)
+		}
+		x.TokPos = l.tok(2)
+		l.tok(5)
+		l.expr(x.X)
+		l.block(x.Body)
+	case *goast.SwitchStmt:
+		x.Switch = l.tok(6)
+		l.expr(x.Tag)
+		l.block(x.Body)
+	case *goast.TypeSwitchStmt:
+		x.Switch = l.tok(6)
+		l.stmt(x.Assign)
+		l.block(x.Body)
+	case *goast.SelectStmt:
+		x.Select = l.tok(6)
+		l.block(x.Body)
+	case *goast.CaseClause:
+		x.Case = l.tok(4)
+		for _, e := range x.List {
+			l.expr(e)
+		}
+		x.Colon = l.tok(1)
+		l.stmts(x.Body)
+	case *goast.CommClause:
+		x.Case = l.tok(4)
+		l.stmt(x.Comm)
+		x.Colon = l.tok(1)
+		l.stmts(x.Body)
+	case *goast.SendStmt:
+		l.expr(x.Chan)
+		x.Arrow = l.tok(2)
+		l.expr(x.Value)
+	}
+}
+
+func (l *layout) expr(e goast.Expr) {
+	switch x := e.(type) {
+	case *goast.Ident:
+		x.NamePos = l.tok(len(x.Name))
+	case *goast.BasicLit:
+		x.ValuePos = l.text(x.Value)
+	case *goast.SelectorExpr:
+		l.expr(x.X)
+		x.Sel.NamePos = l.tok(len(x.Sel.Name))
+	case *goast.StarExpr:
+		x.Star = l.tok(1)
+		l.expr(x.X)
+	case *goast.UnaryExpr:
+		x.OpPos = l.tok(len(x.Op.String()))
+		l.expr(x.X)
+	case *goast.BinaryExpr:
+		l.expr(x.X)
+		x.OpPos = l.tok(len(x.Op.String()))
+		l.expr(x.Y)
+	case *goast.IndexExpr:
+		l.expr(x.X)
+		x.Lbrack = l.tok(1)
+		l.expr(x.Index)
+		x.Rbrack = l.tok(1)
+	case *goast.TypeAssertExpr:
+		l.expr(x.X)
+		x.Lparen = l.tok(2)
+		if x.Type != nil {
+			l.expr(x.Type)
+		} else {
+			l.tok(4)
+		}
+		x.Rparen = l.tok(1)
+	case *goast.ArrayType:
+		x.Lbrack = l.tok(2)
+		l.expr(x.Elt)
+	case *goast.MapType:
+		x.Map = l.tok(3)
+		l.expr(x.Key)
+		l.expr(x.Value)
+	case *goast.ChanType:
+		x.Begin = l.tok(len("<-chan"))
+		l.expr(x.Value)
+	case *goast.Ellipsis:
+		x.Ellipsis = l.tok(3)
+		l.expr(x.Elt)
+	case *goast.StructType:
+		x.Struct = l.tok(6)
+		l.members(x.Fields)
+	case *goast.InterfaceType:
+		x.Interface = l.tok(9)
+		l.members(x.Methods)
+	case *goast.FuncLit:
+		x.Type.Func = l.tok(4)
+		l.signature(x.Type)
+		l.block(x.Body)
+	case *goast.KeyValueExpr:
+		l.expr(x.Key)
+		x.Colon = l.tok(1)
+		l.expr(x.Value)
+	case *goast.CallExpr:
+		l.expr(x.Fun)
+		x.Lparen = l.tok(1)
+		for _, arg := range x.Args {
+			l.expr(arg)
+		}
+		if x.Ellipsis.IsValid() {
+			x.Ellipsis = l.tok(3)
+		}
+		x.Rparen = l.tok(1)
+	case *goast.CompositeLit:
+		if x.Type != nil {
+			l.expr(x.Type)
+		}
+		x.Lbrace = l.tok(1)
+		multiline := l.multiline[x]
+		for _, elt := range x.Elts {
+			if multiline {
+				l.nl()
+			}
+			l.expr(elt)
+
+			// Line comments follow the comma
+			if text, ok := l.comments[elt]; ok {
+				l.tok(1)
+				l.commentGroup(&goast.CommentGroup{List: []*goast.Comment{{Text: text}}})
+			}
+		}
+		if multiline {
+			l.nl()
+		}
+		x.Rbrace = l.tok(1)
+	}
+}
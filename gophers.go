@@ -0,0 +1,356 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/gqlc/graphql/ast"
+	goast "go/ast"
+	gotoken "go/token"
+)
+
+// Backends i.e. the GraphQL libraries code can be generated for
+const (
+	// backendGraphQLGo generates types for github.com/graphql-go/graphql
+	backendGraphQLGo = "graphql-go"
+
+	// backendGraphGophers generates resolvers for github.com/graph-gophers/graphql-go
+	backendGraphGophers = "graph-gophers"
+
+	// backendGqlgen generates models and resolvers for github.com/99designs/gqlgen
+	backendGqlgen = "gqlgen"
+
+	// backendExecutor generates models, resolvers and an executor for github.com/gqlc/golang/exec
+	backendExecutor = "executor"
+)
+
+const gophersImport = "github.com/graph-gophers/graphql-go"
+
+// gophersBackend generates the schema, as parsed by graph-gophers, along with a
+// resolver interface for every object, interface and union type. Arguments, inputs,
+// enums and custom scalars are generated as the Go types it unpacks.
+type gophersBackend struct {
+	BaseBackend
+}
+
+// Header prints the schema, which graph-gophers parses itself, so there are no models or resolvers.
+func (gophersBackend) Header(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	if err := g.checkRootFields(schema); err != nil {
+		return err
+	}
+	g.models, g.resolvers = false, false
+
+	g.addImport(gophersImport)
+	return g.printSchemaConst(schema, types, g.opts.Descriptions)
+}
+
+// Schema prints the root resolver, which resolves all root operations.
+func (gophersBackend) Schema(g *Generator, schema *ast.TypeDecl) error {
+	if len(g.roots) == 0 {
+		return nil
+	}
+
+	b := newBuilder()
+	var roots []*goast.Field
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		if name := rootOpType(schema, op); name != "" {
+			roots = append(roots, b.member(nil, "", b.name(name+"Resolver")))
+		}
+	}
+
+	params := []*goast.Field{
+		b.param("resolver", b.name("RootResolver")),
+		b.param("opts", &goast.Ellipsis{Elt: b.name("graphql.SchemaOpt")}),
+	}
+	return g.printDecls(b,
+		b.typeDecl(b.doc("RootResolver resolves the root operation types of the schema."), "RootResolver", b.interfaceType(roots...)),
+		b.docFunc("ParseSchema parses the schema, resolving its root operations with the given resolver.", "ParseSchema",
+			b.funcType(params, b.typ("*graphql.Schema"), b.name("error")),
+			b.ret(b.spread(b.call("graphql.ParseSchema", b.name("Schema"), b.name("resolver"), b.name("opts")))),
+		),
+	)
+}
+
+func (gophersBackend) Scalar(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b := newBuilder()
+	return g.printDecls(b, g.gophersScalar(b, descrDoc(b, g.opts.Descriptions, d.Doc), ts.Name.Name)...)
+}
+
+func (gophersBackend) Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	name, fields, descr := ts.Name.Name, ts.Type.(*ast.TypeSpec_Object).Object.Fields, g.opts.Descriptions
+
+	b := newBuilder()
+	doc := b.doc(name + "Resolver resolves the fields of " + name + ".")
+	if name == g.subscription {
+		doc = b.doc(name+"Resolver subscribes to the fields of "+name+". The event channels",
+			"must be closed by the resolver, at the latest when ctx is done.")
+	}
+	decls := g.gophersArgs(b, name, fields, descr)
+	decls = append(decls, b.typeDecl(doc, name+"Resolver", b.interfaceType(g.gophersMethods(b, name, fields, descr)...)))
+	return g.printDecls(b, decls...)
+}
+
+func (gophersBackend) Interface(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	name, fields, descr := ts.Name.Name, ts.Type.(*ast.TypeSpec_Interface).Interface.Fields, g.opts.Descriptions
+
+	b := newBuilder()
+	doc := b.doc(name + "Resolver resolves the fields of " + name + ", or asserts it to one of its object types.")
+	methods := append(g.gophersMethods(b, name, fields, descr), g.gophersAssertions(b, name)...)
+	decls := g.gophersArgs(b, name, fields, descr)
+	decls = append(decls, b.typeDecl(doc, name+"Resolver", b.interfaceType(methods...)))
+	return g.printDecls(b, decls...)
+}
+
+func (gophersBackend) Union(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	name := ts.Name.Name
+
+	b := newBuilder()
+	doc := b.doc(name + "Resolver asserts " + name + " to one of its object types.")
+	return g.printDecls(b, b.typeDecl(doc, name+"Resolver", b.interfaceType(g.gophersAssertions(b, name)...)))
+}
+
+func (gophersBackend) Enum(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	b := newBuilder()
+	return g.printDecls(b, g.enumDecls(b, d, ts.Name.Name, ts.Type.(*ast.TypeSpec_Enum).Enum.Values, g.opts.Descriptions)...)
+}
+
+func (gophersBackend) Input(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	descr := g.opts.Descriptions
+
+	b := newBuilder()
+	var fields []*goast.Field
+	for _, f := range ts.Type.(*ast.TypeSpec_Input).Input.Fields.List {
+		fields = append(fields, b.member(descrDoc(b, descr, f.Doc), goName(f.Name.Name), b.typ(g.gophersInputType(f))))
+	}
+	return g.printDecls(b, b.typeDecl(descrDoc(b, descr, d.Doc), ts.Name.Name, b.structType(fields...)))
+}
+
+// checkRootFields returns an error if the root operation types have fields with the same
+// name, since graph-gophers resolves all root operations with a single resolver.
+func (g *Generator) checkRootFields(schema *ast.TypeDecl) error {
+	fields := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		name := rootOpType(schema, op)
+		obj, ok := g.typeSpecs[name].GetType().(*ast.TypeSpec_Object)
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		for _, f := range obj.Object.Fields.List {
+			method := goName(f.Name.Name)
+			if prev, ok := fields[method]; ok {
+				return fmt.Errorf("%s and %s.%s are both resolved by RootResolver.%s", prev, name, f.Name.Name, method)
+			}
+			fields[method] = name + "." + f.Name.Name
+		}
+	}
+	return nil
+}
+
+// gophersArgs returns the argument structs of the given fields.
+func (g *Generator) gophersArgs(b *builder, typ string, fields *ast.FieldList, descr bool) []goast.Decl {
+	var decls []goast.Decl
+	for _, f := range fields.List {
+		if f.Args.NumValues() == 0 {
+			continue
+		}
+
+		args := make([]*goast.Field, len(f.Args.List))
+		for i, a := range f.Args.List {
+			args[i] = b.member(descrDoc(b, descr, a.Doc), goName(a.Name.Name), b.typ(g.gophersInputType(a)))
+		}
+		doc := b.doc(argsName(typ, f) + " contains the arguments of " + typ + "." + f.Name.Name + ".")
+		decls = append(decls, b.typeDecl(doc, argsName(typ, f), b.structType(args...)))
+	}
+	return decls
+}
+
+// gophersMethods returns the resolver methods of the given fields. Subscription
+// fields return a channel of events, rather than a single value.
+func (g *Generator) gophersMethods(b *builder, typ string, fields *ast.FieldList, descr bool) []*goast.Field {
+	g.addImport("context")
+
+	methods := make([]*goast.Field, len(fields.List))
+	for i, f := range fields.List {
+		var params []*goast.Field
+		if f.Args.NumValues() > 0 {
+			params = append(params, b.param("args", b.name(argsName(typ, f))))
+		}
+		methods[i] = g.resolverMethod(b, descrDoc(b, descr, f.Doc), typ, f, params, g.gophersType(fieldType(f), false))
+	}
+	return methods
+}
+
+// gophersAssertions returns the methods which assert the
+// resolver of an interface or union to one of its object types.
+func (g *Generator) gophersAssertions(b *builder, typ string) []*goast.Field {
+	var methods []*goast.Field
+	for _, obj := range g.implementers[typ] {
+		methods = append(methods, b.member(nil, "To"+obj, b.funcType(nil, b.name(obj+"Resolver"), b.name("bool"))))
+	}
+	return methods
+}
+
+// gophersScalar returns a custom scalar as a type implementing graph-gophers'
+// decode.Unmarshaler and json.Marshaler, which wraps the scalars' Go type.
+func (g *Generator) gophersScalar(b *builder, doc *goast.CommentGroup, name string) []goast.Decl {
+	g.addImport("encoding/json")
+
+	s, ok := g.scalars[name]
+	if !ok {
+		s = wellKnownScalars["JSON"]
+	}
+	if s.path != "" {
+		g.addImport(s.path)
+	}
+	if s.kind != "json" {
+		g.addImport("fmt")
+	}
+
+	mismatch := func() goast.Stmt {
+		return b.ret(b.call("fmt.Errorf", b.str("expected "+name+" but got %T"), b.name("input")))
+	}
+	var unmarshal []goast.Stmt
+	switch s.kind {
+	case "json":
+		unmarshal = []goast.Stmt{
+			b.assign(b.name("s.Value"), b.name("input")),
+			b.ret(b.name("nil")),
+		}
+	case "", "url":
+		unmarshal = []goast.Stmt{
+			b.define("str, ok", b.assert(b.name("input"), b.name("string"))),
+			b.ifStmt(nil, b.not(b.name("ok")), mismatch()),
+		}
+		if s.kind == "url" {
+			unmarshal = append(unmarshal,
+				b.define("v, err", b.call("url.Parse", b.name("str"))),
+				b.ifErr(b.name("err")),
+				b.assign(b.name("s.Value"), b.star(b.name("v"))),
+				b.ret(b.name("nil")),
+			)
+			break
+		}
+		unmarshal = append(unmarshal, b.ret(b.call("s.Value.UnmarshalText", &goast.CallExpr{Fun: b.typ("[]byte"), Args: []goast.Expr{b.name("str")}})))
+	case "string", "bool":
+		unmarshal = []goast.Stmt{
+			b.define("v, ok", b.assert(b.name("input"), b.name(s.kind))),
+			b.ifStmt(nil, b.not(b.name("ok")), mismatch()),
+			b.assign(b.name("s.Value"), b.call(s.typ, b.name("v"))),
+			b.ret(b.name("nil")),
+		}
+	default:
+		// Integers may also be given as strings, since they can exceed the precision of JSON numbers
+		clauses := []goast.Stmt{
+			b.caseClause([]goast.Expr{b.name("int32")}, b.assign(b.name("s.Value"), b.call(s.typ, b.name("v")))),
+			b.caseClause([]goast.Expr{b.name("float64")}, b.assign(b.name("s.Value"), b.call(s.typ, b.name("v")))),
+		}
+		if s.kind != "float" {
+			g.addImport("strconv")
+
+			parse := b.call("strconv.ParseInt", b.name("v"), b.lit(gotoken.INT, "10"), b.lit(gotoken.INT, s.bits))
+			if s.kind == "uint" {
+				parse = b.call("strconv.ParseUint", b.name("v"), b.lit(gotoken.INT, "10"), b.lit(gotoken.INT, s.bits))
+			}
+			clauses = append(clauses, b.caseClause([]goast.Expr{b.name("string")},
+				b.define("n, err", parse),
+				b.ifErr(b.name("err")),
+				b.assign(b.name("s.Value"), b.call(s.typ, b.name("n"))),
+			))
+		}
+		unmarshal = []goast.Stmt{
+			b.typeSwitch("v", b.name("input"), append(clauses, b.caseClause(nil, mismatch()))...),
+			b.ret(b.name("nil")),
+		}
+	}
+
+	var marshal []goast.Stmt
+	switch s.kind {
+	case "":
+		marshal = []goast.Stmt{
+			b.define("b, err", b.call("s.Value.MarshalText")),
+			b.ifErr(b.name("nil"), b.name("err")),
+			b.ret(b.call("json.Marshal", b.call("string", b.name("b")))),
+		}
+	case "url":
+		marshal = []goast.Stmt{b.ret(b.call("json.Marshal", b.call("s.Value.String")))}
+	default:
+		marshal = []goast.Stmt{b.ret(b.call("json.Marshal", b.name("s.Value")))}
+	}
+
+	return []goast.Decl{
+		b.typeDecl(doc, name, b.structType(b.member(nil, "Value", b.typ(s.typ)))),
+		b.shortMethod(b.doc("ImplementsGraphQLType implements decode.Unmarshaler."), b.param("", b.name(name)), "ImplementsGraphQLType",
+			b.funcType([]*goast.Field{b.param("name", b.name("string"))}, b.name("bool")),
+			b.ret(b.binary(b.name("name"), gotoken.EQL, b.str(name))),
+		),
+		b.method(b.doc("UnmarshalGraphQL implements decode.Unmarshaler."), b.param("s", b.star(b.name(name))), "UnmarshalGraphQL",
+			b.funcType([]*goast.Field{b.param("input", anyType())}, b.name("error")),
+			unmarshal...,
+		),
+		b.method(b.doc("MarshalJSON implements json.Marshaler."), b.param("s", b.name(name)), "MarshalJSON",
+			b.funcType(nil, b.typ("[]byte"), b.name("error")),
+			marshal...,
+		),
+	}
+}
+
+// gophersInputType returns the Go type of an argument or input field. Values with
+// a default are never null, so graph-gophers unpacks them as non-null values.
+func (g *Generator) gophersInputType(a *ast.InputValue) string {
+	return g.gophersType(inputType(a), a.Default != nil)
+}
+
+// gophersType returns the Go type which graph-gophers maps the given GraphQL type to. Objects,
+// interfaces and unions are represented by their resolver interfaces, while anything else
+// is only referenced by pointer when it's nullable.
+func (g *Generator) gophersType(typ interface{}, nonNull bool) string {
+	var gt string
+	switch v := typ.(type) {
+	case *ast.Ident:
+		switch v.Name {
+		case "Int":
+			gt = "int32"
+		case "Float":
+			gt = "float64"
+		case "String":
+			gt = "string"
+		case "Boolean":
+			gt = "bool"
+		case "ID":
+			g.addImport(gophersImport)
+			gt = "graphql.ID"
+		default:
+			gt = v.Name
+			switch g.typeSpecs[v.Name].GetType().(type) {
+			case *ast.TypeSpec_Object, *ast.TypeSpec_Interface, *ast.TypeSpec_Union:
+				return v.Name + "Resolver"
+			}
+		}
+	case *ast.List:
+		var elem interface{}
+		switch w := v.Type.(type) {
+		case *ast.List_Ident:
+			elem = w.Ident
+		case *ast.List_List:
+			elem = w.List
+		case *ast.List_NonNull:
+			elem = w.NonNull
+		}
+		gt = "[]" + g.gophersType(elem, false)
+	case *ast.NonNull:
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			return g.gophersType(w.Ident, true)
+		case *ast.NonNull_List:
+			return g.gophersType(w.List, true)
+		}
+	default:
+		return "interface{}"
+	}
+
+	if nonNull {
+		return gt
+	}
+	return "*" + gt
+}
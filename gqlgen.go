@@ -0,0 +1,200 @@
+package golang
+
+import (
+	"github.com/gqlc/graphql/ast"
+	goast "go/ast"
+	gotoken "go/token"
+)
+
+// gqlgenBackend generates the schema, along with models and resolver interfaces in the shape
+// gqlgen's executor expects. Fields of root operation types and fields with arguments are
+// resolved by their objects' resolver, while any other field is bound to its model.
+type gqlgenBackend struct {
+	BaseBackend
+
+	// resolved contains the objects with resolvers
+	resolved map[string]bool
+}
+
+// Header prints the schema, which gqlgen parses itself, along with the root of the resolvers.
+func (b *gqlgenBackend) Header(g *Generator, schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	g.models, g.resolvers = true, false
+	if err := g.printSchemaConst(schema, types, g.opts.Descriptions); err != nil {
+		return err
+	}
+
+	// Collect the objects with resolvers
+	var resolved []string
+	b.resolved = make(map[string]bool)
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok || isCompilerType(d, ts.TypeSpec) {
+			continue
+		}
+
+		obj, ok := ts.TypeSpec.Type.(*ast.TypeSpec_Object)
+		if !ok {
+			continue
+		}
+		for _, f := range obj.Object.Fields.List {
+			if g.roots[ts.TypeSpec.Name.Name] || f.Args.NumValues() > 0 {
+				resolved = append(resolved, ts.TypeSpec.Name.Name)
+				b.resolved[ts.TypeSpec.Name.Name] = true
+				break
+			}
+		}
+	}
+
+	if len(resolved) == 0 {
+		return nil
+	}
+	return g.printResolverRoot(resolved)
+}
+
+// printResolverRoot prints the interface which returns the resolvers of the given objects.
+func (g *Generator) printResolverRoot(resolved []string) error {
+	b := newBuilder()
+	methods := make([]*goast.Field, len(resolved))
+	for i, name := range resolved {
+		methods[i] = b.member(nil, name, b.funcType(nil, b.name(name+"Resolver")))
+	}
+
+	g.P()
+	return g.printDecl(b, b.typeDecl(b.doc("ResolverRoot returns the resolvers of the object types."), "ResolverRoot", b.interfaceType(methods...)))
+}
+
+// Object prints the resolver interface of an object, if it has any resolved fields.
+func (b *gqlgenBackend) Object(g *Generator, d *ast.TypeDecl, ts *ast.TypeSpec) error {
+	name := ts.Name.Name
+	if !b.resolved[name] {
+		return nil
+	}
+	return g.printGqlgenResolver(name, ts.Type.(*ast.TypeSpec_Object).Object.Fields)
+}
+
+// printGqlgenResolver prints the resolver interface of an object. The resolvers of root
+// operation types resolve every field, while any other resolves the fields with arguments.
+func (g *Generator) printGqlgenResolver(name string, fields *ast.FieldList) error {
+	g.addImport("context")
+
+	b := newBuilder()
+	var doc *goast.CommentGroup
+	switch {
+	case name == g.subscription:
+		doc = b.doc(name+"Resolver subscribes to the fields of "+name+". The event channels",
+			"must be closed by the resolver, at the latest when ctx is done.")
+	case g.roots[name]:
+		doc = b.doc(name + "Resolver resolves the fields of " + name + ".")
+	default:
+		doc = b.doc(name + "Resolver resolves the fields of " + name + " which have arguments.")
+	}
+
+	var methods []*goast.Field
+	for _, f := range fields.List {
+		if !g.roots[name] && f.Args.NumValues() == 0 {
+			continue
+		}
+
+		var params []*goast.Field
+		if !g.roots[name] {
+			params = append(params, b.param("obj", b.star(b.name(name))))
+		}
+		for _, a := range f.Args.GetList() {
+			params = append(params, b.param(gqlgenArgName(a.Name.Name), b.typ(g.gqlgenArgType(a))))
+		}
+		doc := descrDoc(b, g.opts.Descriptions, f.Doc)
+		methods = append(methods, g.resolverMethod(b, doc, name, f, params, g.goType(fieldType(f), false)))
+	}
+
+	g.P()
+	return g.printDecl(b, b.typeDecl(doc, name+"Resolver", b.interfaceType(methods...)))
+}
+
+// gqlgenArgName returns the name of the resolver parameter of an argument.
+// Like gqlgen, arguments named after Go keywords are suffixed by Arg.
+func gqlgenArgName(name string) string {
+	if gotoken.Lookup(name).IsKeyword() {
+		return name + "Arg"
+	}
+	return name
+}
+
+// gqlgenArgType returns the Go type of an argument. Unlike in models, non-null
+// input objects are passed by value.
+func (g *Generator) gqlgenArgType(a *ast.InputValue) string {
+	if nn, ok := inputType(a).(*ast.NonNull); ok {
+		if id, ok := nn.Type.(*ast.NonNull_Ident); ok {
+			if _, ok = g.typeSpecs[id.Ident.Name].GetType().(*ast.TypeSpec_Input); ok {
+				return id.Ident.Name
+			}
+		}
+	}
+	return g.goType(inputType(a), false)
+}
+
+// gqlgenEnum returns the methods gqlgen expects of an enum, which
+// validate, unmarshal and marshal its values.
+func (g *Generator) gqlgenEnum(b *builder, name string, values *ast.FieldList) []goast.Decl {
+	g.addImport("fmt")
+	g.addImport("io")
+	g.addImport("strconv")
+
+	all := b.block(&goast.ArrayType{Elt: b.name(name)})
+	consts := make([]goast.Expr, len(values.List))
+	for i, ev := range values.List {
+		all.Elts = append(all.Elts, b.name(enumConst(name, ev.Name.Name)))
+		consts[i] = b.name(enumConst(name, ev.Name.Name))
+	}
+
+	recv := b.param("e", b.name(name))
+	errorf := func(format string, args ...goast.Expr) goast.Stmt {
+		return b.ret(b.call("fmt.Errorf", append([]goast.Expr{b.str(format)}, args...)...))
+	}
+	return []goast.Decl{
+		b.withDoc(b.doc("All"+name+" contains all values of "+name+"."), b.varDecl("All"+name, all)),
+		b.method(b.doc("IsValid reports whether e is a value of "+name+"."), recv, "IsValid", b.funcType(nil, b.name("bool")),
+			b.switchStmt(b.name("e"), b.caseClause(consts, b.ret(b.name("true")))),
+			b.ret(b.name("false")),
+		),
+		b.method(b.doc("String returns the name of the value."), recv, "String", b.funcType(nil, b.name("string")),
+			b.ret(b.call("string", b.name("e"))),
+		),
+		b.method(b.doc("UnmarshalGQL implements graphql.Unmarshaler."), b.param("e", b.star(b.name(name))), "UnmarshalGQL",
+			b.funcType([]*goast.Field{b.param("v", anyType())}, b.name("error")),
+			b.define("str, ok", b.assert(b.name("v"), b.name("string"))),
+			b.ifStmt(nil, b.not(b.name("ok")), errorf("enums must be strings")),
+			b.gap(b.assign(b.star(b.name("e")), b.call(name, b.name("str")))),
+			b.ifStmt(nil, b.not(b.call("e.IsValid")), errorf("%s is not a valid "+name, b.name("str"))),
+			b.ret(b.name("nil")),
+		),
+		b.method(b.doc("MarshalGQL implements graphql.Marshaler."), recv, "MarshalGQL",
+			b.funcType([]*goast.Field{b.param("w", b.name("io.Writer"))}),
+			b.exprStmt(b.call("fmt.Fprint", b.name("w"), b.call("strconv.Quote", b.call("e.String")))),
+		),
+	}
+}
+
+// gqlgenScalar returns a custom scalar without a Go type as a type implementing
+// gqlgen's graphql.Marshaler and graphql.Unmarshaler, which holds the JSON value
+// of the scalar.
+func (g *Generator) gqlgenScalar(b *builder, doc *goast.CommentGroup, name string) []goast.Decl {
+	g.addImport("encoding/json")
+	g.addImport("io")
+
+	return []goast.Decl{
+		b.typeDecl(doc, name, b.structType(b.member(nil, "Value", anyType()))),
+		b.method(b.doc("UnmarshalGQL implements graphql.Unmarshaler."), b.param("s", b.star(b.name(name))), "UnmarshalGQL",
+			b.funcType([]*goast.Field{b.param("v", anyType())}, b.name("error")),
+			b.assign(b.name("s.Value"), b.name("v")),
+			b.ret(b.name("nil")),
+		),
+		b.method(b.doc("MarshalGQL implements graphql.Marshaler."), b.param("s", b.name(name)), "MarshalGQL",
+			b.funcType([]*goast.Field{b.param("w", b.name("io.Writer"))}),
+			b.define("b, err", b.call("json.Marshal", b.name("s.Value"))),
+			b.ifStmt(nil, b.binary(b.name("err"), gotoken.NEQ, b.name("nil")),
+				b.assign(b.name("b"), &goast.CallExpr{Fun: b.typ("[]byte"), Args: []goast.Expr{b.str("null")}}),
+			),
+			b.exprStmt(b.call("w.Write", b.name("b"))),
+		),
+	}
+}
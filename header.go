@@ -0,0 +1,57 @@
+package golang
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath is the module path of the generator, as found in build info.
+const modulePath = "github.com/gqlc/golang"
+
+// fileHeader returns the comment which marks files as generated, as per golang.org/s/generatedcode,
+// along with their source. The version and schema hash are only included when given.
+func fileHeader(source, version, hash string) string {
+	var b strings.Builder
+	b.WriteString("// Code generated by gqlc-go. DO NOT EDIT.\n")
+	b.WriteString("// source: " + source + "\n")
+	if version != "" {
+		b.WriteString("// version: " + version + "\n")
+	}
+	if hash != "" {
+		b.WriteString("// schema: " + hash + "\n")
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// generatorVersion returns the module version of the generator, as built into the binary.
+func generatorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if dep.Version != "" {
+			return dep.Version
+		}
+	}
+	return "(devel)"
+}
+
+// schemaHash returns the SHA-256 hash of a schema, in its canonical SDL form.
+func schemaHash(sdl string) string {
+	sum := sha256.Sum256([]byte(sdl))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
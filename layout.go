@@ -0,0 +1,148 @@
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gqlc/compiler"
+	"github.com/gqlc/graphql/ast"
+	"strings"
+	"unicode"
+)
+
+// Layouts of the generated files
+const (
+	// layoutSingle generates a single file, named after the document
+	layoutSingle = "single"
+
+	// layoutPerType generates a file for every type e.g. user.gen.go
+	layoutPerType = "per-type"
+
+	// layoutPerKind generates a file for every kind of type e.g. objects.gen.go
+	layoutPerKind = "per-kind"
+)
+
+// outputFile is a generated file along with the packages it imports.
+type outputFile struct {
+	name    string
+	imports map[string]bool
+	bytes.Buffer
+}
+
+// typeFile returns the name, without extension, of the file a type is generated into.
+func (g *Generator) typeFile(ts *ast.TypeSpec) string {
+	switch g.layout {
+	case layoutPerType:
+		return snakeCase(ts.Name.Name) + ".gen"
+	case layoutPerKind:
+		switch ts.Type.(type) {
+		case *ast.TypeSpec_Scalar:
+			return "scalars.gen"
+		case *ast.TypeSpec_Object:
+			return "objects.gen"
+		case *ast.TypeSpec_Interface:
+			return "interfaces.gen"
+		case *ast.TypeSpec_Union:
+			return "unions.gen"
+		case *ast.TypeSpec_Enum:
+			return "enums.gen"
+		case *ast.TypeSpec_Input:
+			return "inputs.gen"
+		case *ast.TypeSpec_Directive:
+			return "directives.gen"
+		}
+	}
+	return g.fileName
+}
+
+// section directs any following output into the given file. The
+// output so far, along with its imports, is moved to the current file.
+func (g *Generator) section(name string) {
+	if name == g.file {
+		return
+	}
+	g.flush()
+	g.file = name
+}
+
+// flush moves the output so far, along with its imports, to the current file.
+func (g *Generator) flush() {
+	if g.Len() == 0 && len(g.imports) == 0 {
+		return
+	}
+
+	var f *outputFile
+	for _, of := range g.files {
+		if of.name == g.file {
+			f = of
+			break
+		}
+	}
+	if f == nil {
+		f = &outputFile{name: g.file, imports: make(map[string]bool)}
+		g.files = append(g.files, f)
+	}
+
+	for path := range g.imports {
+		f.imports[path] = true
+	}
+	g.imports = nil
+	f.Write(g.Bytes())
+	g.Buffer.Reset()
+}
+
+// writeFiles writes every generated file, prepended by the given header, its package clause and imports.
+func (g *Generator) writeFiles(gCtx compiler.GeneratorContext, gOpts *Options, header string) error {
+	g.flush()
+
+	for _, f := range g.files {
+		if g.backend == backendGraphQLGo {
+			f.imports[graphqlImport] = true
+		}
+
+		// Prepend package and imports to the generated output
+		var src bytes.Buffer
+		src.WriteString(header)
+		writeHeader(&src, []byte(gOpts.Package), f.imports)
+		f.WriteTo(&src)
+
+		// Format generated output
+		out := src.Bytes()
+		if gOpts.Format {
+			var err error
+			out, err = formatSource(out)
+			if err != nil {
+				return fmt.Errorf("%s.go: %s", f.name, err)
+			}
+		}
+
+		// Write generated output
+		goFile, err := gCtx.Open(f.name + ".go")
+		if err != nil {
+			return err
+		}
+		_, err = goFile.Write(out)
+		goFile.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snakeCase converts a GraphQL name into a file name e.g. UserID -> user_id.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			prev, next := rune(name[i-1]), rune(0)
+			if i+1 < len(name) {
+				next = rune(name[i+1])
+			}
+			if unicode.IsLower(prev) || (unicode.IsUpper(prev) && unicode.IsLower(next)) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
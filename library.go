@@ -0,0 +1,100 @@
+package golang
+
+import (
+	goast "go/ast"
+	gotoken "go/token"
+	"sort"
+	"strings"
+)
+
+// packageName returns the name of the package with the given import path,
+// i.e. its last path element, skipping any major version suffix.
+func packageName(path string) string {
+	elems := strings.Split(path, "/")
+	pkg := elems[len(elems)-1]
+	if len(elems) > 1 && len(pkg) > 1 && pkg[0] == 'v' && strings.Trim(pkg[1:], "0123456789") == "" {
+		pkg = elems[len(elems)-2]
+	}
+	return strings.Replace(pkg, "-", "_", -1)
+}
+
+// isLibraryType reports whether a type is generated by a type library, instead of this package.
+func (g *Generator) isLibraryType(name string) bool {
+	_, ok := g.libraries[name]
+	return ok
+}
+
+// qualify returns the given Go identifier of a type, qualified by
+// its package when the type is generated by a type library.
+func (g *Generator) qualify(typ, ident string) string {
+	path, ok := g.libraries[typ]
+	if !ok {
+		return ident
+	}
+
+	g.addImport(path)
+	return packageName(path) + "." + ident
+}
+
+// typeVar returns the name of the graphql-go type variable of a type.
+func (g *Generator) typeVar(typ string) string {
+	return g.qualify(typ, typ+string(typeSuffix))
+}
+
+// printRegisterTypes prints the func which registers the types of a
+// type library, such that they can be composed into another packages' schema.
+func (g *Generator) printRegisterTypes(named []string) error {
+	b := newBuilder()
+
+	var body []goast.Stmt
+	for _, name := range named {
+		body = append(body, b.assign(b.index(b.name("types"), b.str(name)), b.name(name+string(typeSuffix))))
+	}
+
+	typ := b.funcType([]*goast.Field{b.param("types", &goast.MapType{Key: b.name("string"), Value: b.name("graphql.Type")})})
+	return g.printDecl(b, b.docFunc("RegisterTypes adds the types of this package to the given types, by name.",
+		"RegisterTypes", typ, body...))
+}
+
+// libraryTypes returns the collection of the schemas' types, including
+// the types registered by every type library the schema is composed of.
+func (g *Generator) libraryTypes(b *builder, named []string) []goast.Stmt {
+	paths := make(map[string]bool, len(g.libraries))
+	for _, path := range g.libraries {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	typeMap := &goast.MapType{Key: b.name("string"), Value: b.name("graphql.Type")}
+	stmts := []goast.Stmt{b.define("libraryTypes", b.call("make", typeMap))}
+	for _, path := range sorted {
+		g.addImport(path)
+		stmts = append(stmts, b.exprStmt(b.call(packageName(path)+".RegisterTypes", b.name("libraryTypes"))))
+	}
+
+	// Map iteration order is random, so the types are appended by their names
+	g.addImport("sort")
+	names := b.call("make", &goast.ArrayType{Elt: b.name("string")}, b.lit(gotoken.INT, "0"), b.call("len", b.name("libraryTypes")))
+	stmts = append(stmts,
+		b.define("names", names),
+		b.rangeStmt("name", "", b.name("libraryTypes"),
+			b.assign(b.name("names"), b.call("append", b.name("names"), b.name("name"))),
+		),
+		b.exprStmt(b.call("sort.Strings", b.name("names"))),
+	)
+
+	types := b.block(&goast.ArrayType{Elt: b.name("graphql.Type")})
+	for _, name := range named {
+		types.Elts = append(types.Elts, b.name(name+string(typeSuffix)))
+	}
+	return append(stmts,
+		b.define("types", types),
+		b.rangeStmt("_", "name", b.name("names"),
+			b.assign(b.name("types"), b.call("append", b.name("types"), b.index(b.name("libraryTypes"), b.name("name")))),
+		),
+	)
+}
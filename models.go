@@ -0,0 +1,379 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/gqlc/graphql/ast"
+	goast "go/ast"
+	gotoken "go/token"
+	"strings"
+	"unicode"
+)
+
+// generateModels generates Go types for every object, interface, union, enum and input type.
+func (g *Generator) generateModels(types []*ast.TypeDecl, descr bool) error {
+	// Collect the abstract types every object belongs to
+	abstracts := make(map[string][]string)
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+
+		switch v := ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Object:
+			for _, inter := range v.Object.Interfaces {
+				abstracts[ts.TypeSpec.Name.Name] = append(abstracts[ts.TypeSpec.Name.Name], inter.Name)
+			}
+		case *ast.TypeSpec_Union:
+			for _, mem := range v.Union.Members {
+				abstracts[mem.Name] = append(abstracts[mem.Name], ts.TypeSpec.Name.Name)
+			}
+		}
+	}
+
+	b := newBuilder()
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+		if _, ok = ts.TypeSpec.Type.(*ast.TypeSpec_Schema); ok {
+			continue
+		}
+		if isCompilerType(d, ts.TypeSpec) || g.isLibraryType(ts.TypeSpec.Name.Name) {
+			continue
+		}
+
+		// Other backends resolve root operation types without a model
+		if (g.backend == backendGqlgen || g.backend == backendExecutor) && g.roots[ts.TypeSpec.Name.Name] {
+			continue
+		}
+
+		name := ts.TypeSpec.Name.Name
+		g.section(g.typeFile(ts.TypeSpec))
+
+		var decls []goast.Decl
+		switch v := ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Scalar:
+			if _, ok := g.scalars[name]; !ok && g.backend == backendGqlgen {
+				decls = g.gqlgenScalar(b, descrDoc(b, descr, d.Doc), name)
+			}
+		case *ast.TypeSpec_Object:
+			fields := make([]*goast.Field, len(v.Object.Fields.List))
+			for i, f := range v.Object.Fields.List {
+				fields[i] = b.jsonField(descrDoc(b, descr, f.Doc), goName(f.Name.Name), b.typ(g.goType(fieldType(f), false)), f.Name.Name)
+			}
+			decls = append(decls, b.typeDecl(descrDoc(b, descr, d.Doc), name, b.structType(fields...)))
+
+			for _, a := range abstracts[name] {
+				decls = append(decls, b.shortMethod(nil, b.param("", b.star(b.name(name))), "Is"+a, b.funcType(nil)))
+			}
+		case *ast.TypeSpec_Input:
+			fields := make([]*goast.Field, len(v.Input.Fields.List))
+			for i, f := range v.Input.Fields.List {
+				fields[i] = b.jsonField(descrDoc(b, descr, f.Doc), goName(f.Name.Name), b.typ(g.goType(inputType(f), false)), f.Name.Name)
+			}
+			decls = append(decls, b.typeDecl(descrDoc(b, descr, d.Doc), name, b.structType(fields...)))
+		case *ast.TypeSpec_Interface, *ast.TypeSpec_Union:
+			method := b.member(nil, "Is"+name, b.funcType(nil))
+			decls = append(decls, b.typeDecl(descrDoc(b, descr, d.Doc), name, b.interfaceType(method)))
+		case *ast.TypeSpec_Enum:
+			decls = g.enumDecls(b, d, name, v.Enum.Values, descr)
+			if g.backend == backendGqlgen {
+				decls = append(decls, g.gqlgenEnum(b, name, v.Enum.Values)...)
+			}
+		}
+
+		if err := g.printDecls(b, decls...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enumDecls returns the declarations of an enum, i.e. a string type along with a const per value.
+func (g *Generator) enumDecls(b *builder, d *ast.TypeDecl, name string, values *ast.FieldList, descr bool) []goast.Decl {
+	consts := make([]goast.Spec, len(values.List))
+	for i, ev := range values.List {
+		consts[i] = b.constSpec(descrDoc(b, descr, ev.Doc), enumConst(name, ev.Name.Name), b.name(name), b.str(ev.Name.Name))
+	}
+	return []goast.Decl{
+		b.typeDecl(descrDoc(b, descr, d.Doc), name, b.name("string")),
+		b.constBlock(consts...),
+	}
+}
+
+// hasModel reports whether a Go type is generated for the given type.
+func (g *Generator) hasModel(ts *ast.TypeSpec) bool {
+	switch ts.Type.(type) {
+	case *ast.TypeSpec_Scalar:
+		if _, ok := g.scalars[ts.Name.Name]; !ok && g.backend == backendGqlgen {
+			return true
+		}
+		return g.backend == backendGraphGophers
+	case *ast.TypeSpec_Enum, *ast.TypeSpec_Input:
+		return g.models || g.backend != backendGraphQLGo
+	case *ast.TypeSpec_Object, *ast.TypeSpec_Interface, *ast.TypeSpec_Union:
+		switch g.backend {
+		case backendGraphGophers:
+			return false
+		case backendGqlgen, backendExecutor:
+			return !g.roots[ts.Name.Name]
+		}
+		return g.models
+	}
+	return false
+}
+
+// checkGoNames returns an error if GraphQL names map to Go identifiers, which are
+// invalid or declared twice, since the generated code wouldn't compile.
+func (g *Generator) checkGoNames(schema *ast.TypeDecl, types []*ast.TypeDecl) error {
+	if g.backend == backendGraphQLGo && !g.models {
+		return nil
+	}
+
+	decls := make(map[string]string)
+	declare := func(name, what string) error {
+		if prev, ok := decls[name]; ok {
+			return fmt.Errorf("%s and %s both map to the Go name %s", prev, what, name)
+		}
+		decls[name] = what
+		return nil
+	}
+	if schema != nil && g.backend != backendExecutor {
+		decls["Schema"] = "the schema"
+	}
+
+	// Collect the methods of the models
+	methods := make(map[string][]string)
+	if g.backend != backendGraphGophers {
+		for abstract, objs := range g.implementers {
+			for _, obj := range objs {
+				methods[obj] = append(methods[obj], "Is"+abstract)
+			}
+		}
+	}
+
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok || isCompilerType(d, ts.TypeSpec) || g.isLibraryType(typeSpecName(ts.TypeSpec)) {
+			continue
+		}
+		name := typeSpecName(ts.TypeSpec)
+
+		var fields *ast.FieldList
+		switch v := ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Schema, *ast.TypeSpec_Directive:
+			continue
+		case *ast.TypeSpec_Scalar:
+			if g.backend == backendExecutor {
+				if err := declare(g.typeVar(name), "type "+name); err != nil {
+					return err
+				}
+			}
+		case *ast.TypeSpec_Object:
+			fields = v.Object.Fields
+		case *ast.TypeSpec_Interface:
+			fields = v.Interface.Fields
+		case *ast.TypeSpec_Input:
+			if err := checkFieldNames(name, inputNames(v.Input.Fields), methods[name]); err != nil {
+				return err
+			}
+		case *ast.TypeSpec_Enum:
+			if !g.hasModel(ts.TypeSpec) {
+				break
+			}
+			for _, ev := range v.Enum.Values.List {
+				if err := declare(enumConst(name, ev.Name.Name), "enum value "+name+"."+ev.Name.Name); err != nil {
+					return err
+				}
+			}
+		}
+
+		if g.backend == backendGraphQLGo {
+			if err := declare(g.typeVar(name), "type "+name); err != nil {
+				return err
+			}
+		}
+		if g.hasModel(ts.TypeSpec) {
+			if err := declare(name, "type "+name); err != nil {
+				return err
+			}
+		}
+
+		if fields == nil {
+			continue
+		}
+		if err := checkFieldNames(name, fieldNames(fields), methods[name]); err != nil {
+			return err
+		}
+		for _, f := range fields.List {
+			if err := checkFieldNames(name+"."+f.Name.Name, inputNames(f.Args), nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkFieldNames returns an error if the given fields don't map to distinct
+// Go identifiers, which don't collide with the methods of their type.
+func checkFieldNames(typ string, fields []*ast.Ident, methods []string) error {
+	members := make(map[string]string, len(fields)+len(methods))
+	for _, m := range methods {
+		members[m] = "method " + typ + "." + m
+	}
+	for _, f := range fields {
+		name := goName(f.Name)
+		if !gotoken.IsIdentifier(name) {
+			return fmt.Errorf("field %s.%s has no Go name", typ, f.Name)
+		}
+		if prev, ok := members[name]; ok {
+			return fmt.Errorf("%s and field %s.%s both map to the Go name %s", prev, typ, f.Name, name)
+		}
+		members[name] = "field " + typ + "." + f.Name
+	}
+	return nil
+}
+
+// goType returns the Go type which represents the given GraphQL type in models.
+// Objects and inputs are always referenced by pointer, while scalars and
+// enums are only referenced by pointer when they're nullable. Custom scalars
+// are represented by interface{}, unless they're mapped to a Go type, or by
+// their generated type for gqlgen.
+func (g *Generator) goType(typ interface{}, nonNull bool) string {
+	switch v := typ.(type) {
+	case *ast.Ident:
+		var gt string
+		switch v.Name {
+		case "Int":
+			gt = "int"
+		case "Float":
+			gt = "float64"
+		case "String", "ID":
+			gt = "string"
+		case "Boolean":
+			gt = "bool"
+		default:
+			ts, ok := g.typeSpecs[v.Name]
+			if !ok {
+				return "*" + v.Name
+			}
+
+			switch ts.Type.(type) {
+			case *ast.TypeSpec_Scalar:
+				s, ok := g.scalars[v.Name]
+				switch {
+				case !ok && g.backend == backendGqlgen:
+					gt = g.qualify(v.Name, v.Name)
+				case !ok || s.kind == "json":
+					return "interface{}"
+				default:
+					if s.path != "" {
+						g.addImport(s.path)
+					}
+					gt = s.typ
+				}
+			case *ast.TypeSpec_Enum:
+				gt = g.qualify(v.Name, v.Name)
+			case *ast.TypeSpec_Interface, *ast.TypeSpec_Union:
+				return g.qualify(v.Name, v.Name)
+			default:
+				return "*" + g.qualify(v.Name, v.Name)
+			}
+		}
+
+		if nonNull {
+			return gt
+		}
+		return "*" + gt
+	case *ast.List:
+		var elem interface{}
+		switch w := v.Type.(type) {
+		case *ast.List_Ident:
+			elem = w.Ident
+		case *ast.List_List:
+			elem = w.List
+		case *ast.List_NonNull:
+			elem = w.NonNull
+		}
+		return "[]" + g.goType(elem, false)
+	case *ast.NonNull:
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			return g.goType(w.Ident, true)
+		case *ast.NonNull_List:
+			return g.goType(w.List, true)
+		}
+	}
+	return "interface{}"
+}
+
+// descrDoc returns the description of a definition as a Go comment, if descriptions are generated.
+func descrDoc(b *builder, descr bool, doc *ast.DocGroup) *goast.CommentGroup {
+	if !descr || doc == nil {
+		return nil
+	}
+
+	text := descrText(doc)
+	if len(text) == 0 {
+		return nil
+	}
+	return b.doc(strings.Split(text, "\n")...)
+}
+
+// commonInitialisms are words which golint expects to be written in all caps.
+var commonInitialisms = map[string]bool{
+	"API":  true,
+	"HTML": true,
+	"HTTP": true,
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"SQL":  true,
+	"URI":  true,
+	"URL":  true,
+	"UUID": true,
+}
+
+// goName converts a GraphQL name into an exported Go identifier,
+// e.g. hasNextPage -> HasNextPage, user_id -> UserID and NORTH -> North.
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if len(part) == 0 {
+			continue
+		}
+
+		// Words in all caps are either initialisms or SCREAMING_CASE
+		if strings.ToUpper(part) == part {
+			if !commonInitialisms[part] {
+				part = part[:1] + strings.ToLower(part[1:])
+			}
+			b.WriteString(part)
+			continue
+		}
+
+		// Split the part into its camel case words
+		start := 0
+		for i := 1; i <= len(part); i++ {
+			if i < len(part) && !unicode.IsUpper(rune(part[i])) {
+				continue
+			}
+
+			word := part[start:i]
+			if upper := strings.ToUpper(word); commonInitialisms[upper] {
+				word = upper
+			}
+			b.WriteString(strings.ToUpper(word[:1]))
+			b.WriteString(word[1:])
+			start = i
+		}
+	}
+	return b.String()
+}
+
+// enumConst returns the name of the Go constant for an enum value.
+func enumConst(enum, value string) string {
+	return enum + goName(value)
+}
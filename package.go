@@ -0,0 +1,104 @@
+package golang
+
+import (
+	"context"
+	"fmt"
+	"github.com/gqlc/compiler"
+	"github.com/gqlc/graphql/ast"
+	"reflect"
+	"strings"
+)
+
+// GeneratePackage generates Go code for a set of documents. All documents targeting the
+// same package are generated into a single file, named after the package, such that types
+// declared by several documents, e.g. due to imports, are only generated once. The documents
+// of a package must agree on its options.
+func (g *Generator) GeneratePackage(ctx context.Context, docs []*ast.Document, opts string) (err error) {
+	g.Lock()
+	defer g.Unlock()
+
+	// Group documents by package, in order of appearance
+	var pkgs []string
+	pkgDocs := make(map[string][]*ast.Document)
+	pkgOpts := make(map[string]*Options)
+	for _, doc := range docs {
+		gOpts, oerr := getOptions(doc, opts)
+		if oerr != nil {
+			return compiler.GeneratorError{DocName: doc.Name, GenName: "go", Msg: oerr.Error()}
+		}
+
+		pkg := gOpts.Package
+		if _, ok := pkgDocs[pkg]; !ok {
+			pkgs = append(pkgs, pkg)
+			pkgOpts[pkg] = gOpts
+		} else if !reflect.DeepEqual(pkgOpts[pkg], gOpts) {
+			return compiler.GeneratorError{
+				DocName: doc.Name,
+				GenName: "go",
+				Msg:     fmt.Sprintf("options of package %s conflict with those of %s", pkg, pkgDocs[pkg][0].Name),
+			}
+		}
+		pkgDocs[pkg] = append(pkgDocs[pkg], doc)
+	}
+
+	for _, pkg := range pkgs {
+		doc, merr := mergeDocuments(pkgDocs[pkg])
+		if merr == nil {
+			merr = g.generate(ctx, doc, pkgOpts[pkg], pkg)
+		}
+		if merr != nil {
+			return compiler.GeneratorError{DocName: docNames(pkgDocs[pkg]), GenName: "go", Msg: merr.Error()}
+		}
+	}
+	return
+}
+
+// docNames returns the names of the given documents, joined by commas.
+func docNames(docs []*ast.Document) string {
+	names := make([]string, len(docs))
+	for i, d := range docs {
+		names[i] = d.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// mergeDocuments merges the given documents into a single document, named after all of them.
+// Types are kept in order of their first declaration, while any later, identical declarations
+// of them are dropped. Extensions are kept, such that they may extend types of any document.
+func mergeDocuments(docs []*ast.Document) (*ast.Document, error) {
+	merged := &ast.Document{Name: docNames(docs)}
+
+	var schemaDoc string
+	declared := make(map[string]string)
+	declaredIn := make(map[string]string)
+	seen := make(map[*ast.TypeDecl]bool)
+	for _, doc := range docs {
+		if doc.Schema != nil && doc.Schema != merged.Schema {
+			if merged.Schema != nil {
+				return nil, fmt.Errorf("multiple schemas declared in %s and %s", schemaDoc, doc.Name)
+			}
+			merged.Schema, schemaDoc = doc.Schema, doc.Name
+		}
+
+		for _, d := range doc.Types {
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+
+			if ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec); ok {
+				// Declarations are compared by their SDL, since their positions differ
+				typ, sdl := typeSpecName(ts.TypeSpec), declSDL(d)
+				if prev, ok := declared[typ]; ok {
+					if prev != sdl {
+						return nil, fmt.Errorf("conflicting declarations of %s in %s and %s", typ, declaredIn[typ], doc.Name)
+					}
+					continue
+				}
+				declared[typ], declaredIn[typ] = sdl, doc.Name
+			}
+			merged.Types = append(merged.Types, d)
+		}
+	}
+	return merged, nil
+}
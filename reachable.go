@@ -0,0 +1,45 @@
+package golang
+
+import "github.com/gqlc/graphql/ast"
+
+// findReachable returns the types which are reachable from the root operation
+// types or the arguments of directives. Interfaces also reach their implementers,
+// since graphql-go only knows about those through SchemaConfig.Types.
+func (g *Generator) findReachable(types []*ast.TypeDecl) map[string]bool {
+	var queue []string
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+
+		switch v := ts.TypeSpec.Type.(type) {
+		case *ast.TypeSpec_Object:
+			if g.roots[ts.TypeSpec.Name.Name] {
+				queue = append(queue, ts.TypeSpec.Name.Name)
+			}
+		case *ast.TypeSpec_Directive:
+			queue = appendInputRefs(queue, v.Directive.Args)
+		}
+	}
+
+	reachable := make(map[string]bool, len(types))
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if reachable[name] {
+			continue
+		}
+		reachable[name] = true
+
+		ts, ok := g.typeSpecs[name]
+		if !ok {
+			continue
+		}
+		queue = append(queue, typeRefs(ts)...)
+		if _, ok = ts.Type.(*ast.TypeSpec_Interface); ok {
+			queue = append(queue, g.implementers[name]...)
+		}
+	}
+	return reachable
+}
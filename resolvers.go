@@ -0,0 +1,256 @@
+package golang
+
+import (
+	"github.com/gqlc/graphql/ast"
+	"github.com/gqlc/graphql/token"
+	goast "go/ast"
+	gotoken "go/token"
+	"strings"
+)
+
+// rootTypes returns the names of the root operation types of a schema.
+func rootTypes(schema *ast.TypeDecl) map[string]bool {
+	roots := make(map[string]bool, 3)
+	if schema == nil {
+		return roots
+	}
+
+	rootOps := schema.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Schema).Schema.RootOps
+	if rootOps == nil {
+		return roots
+	}
+
+	for _, op := range rootOps.List {
+		if id, ok := op.Type.(*ast.Field_Ident); ok {
+			roots[id.Ident.Name] = true
+		}
+	}
+	return roots
+}
+
+// rootOpType returns the name of the root operation type of the given operation, if any.
+func rootOpType(schema *ast.TypeDecl, op string) string {
+	if schema == nil {
+		return ""
+	}
+
+	rootOps := schema.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec.Type.(*ast.TypeSpec_Schema).Schema.RootOps
+	if rootOps == nil {
+		return ""
+	}
+
+	for _, f := range rootOps.List {
+		if id, ok := f.Type.(*ast.Field_Ident); ok && f.Name.Name == op {
+			return id.Ident.Name
+		}
+	}
+	return ""
+}
+
+// implicitSchema returns the schema which is implied by the types named Query, Mutation
+// and Subscription, when a document omits its schema. Without a Query type there's none.
+func implicitSchema(types []*ast.TypeDecl) *ast.TypeDecl {
+	objs := make(map[string]bool, len(types))
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok {
+			continue
+		}
+		if _, ok = ts.TypeSpec.Type.(*ast.TypeSpec_Object); ok {
+			objs[ts.TypeSpec.Name.Name] = true
+		}
+	}
+	if !objs["Query"] {
+		return nil
+	}
+
+	rootOps := &ast.FieldList{}
+	for _, op := range []string{"Query", "Mutation", "Subscription"} {
+		if !objs[op] {
+			continue
+		}
+
+		rootOps.List = append(rootOps.List, &ast.Field{
+			Name: &ast.Ident{Name: strings.ToLower(op)},
+			Type: &ast.Field_Ident{Ident: &ast.Ident{Name: op}},
+		})
+	}
+	return &ast.TypeDecl{
+		Tok: token.Token_SCHEMA,
+		Spec: &ast.TypeDecl_TypeSpec{TypeSpec: &ast.TypeSpec{
+			Type: &ast.TypeSpec_Schema{Schema: &ast.SchemaType{RootOps: rootOps}},
+		}},
+	}
+}
+
+// resolveFunc returns the type of Resolve and Subscribe funcs.
+func resolveFunc(b *builder) *goast.FuncType {
+	return b.funcType([]*goast.Field{b.param("p", b.name("graphql.ResolveParams"))}, anyType(), b.name("error"))
+}
+
+// resolverFunc returns a Resolve func which delegates to the resolver of the given object type.
+// Fields of objects without a resolver are resolved from their source value.
+func (g *Generator) resolverFunc(b *builder, typ string, f *ast.Field) goast.Expr {
+	body := []goast.Stmt{
+		b.ifStmt(nil, b.binary(b.name(typ+"Resolvers"), gotoken.EQL, b.name("nil")),
+			b.ret(b.call("graphql.DefaultResolveFn", b.name("p"))),
+		),
+	}
+
+	args := []goast.Expr{b.name("p.Context")}
+	if !g.roots[typ] {
+		g.addImport("fmt")
+		unexpected := b.call("fmt.Errorf", b.str(typ+"."+f.Name.Name+": unexpected source %T"), b.name("p.Source"))
+		body = append(body,
+			b.define("obj, ok", b.assert(b.name("p.Source"), b.star(b.name(typ)))),
+			b.ifStmt(nil, &goast.UnaryExpr{Op: gotoken.NOT, X: b.name("ok")}, b.ret(b.name("nil"), unexpected)),
+		)
+		args = append(args, b.name("obj"))
+	}
+	if f.Args.NumValues() > 0 {
+		body = append(body,
+			b.define("args, err", b.call("decode"+argsName(typ, f), b.name("p.Args"))),
+			b.ifErr(b.name("nil"), b.name("err")),
+		)
+		args = append(args, b.name("args"))
+	}
+
+	body = append(body, b.ret(b.call(typ+"Resolvers."+goName(f.Name.Name), args...)))
+	return b.funcLit(resolveFunc(b), body...)
+}
+
+// subscribeFuncs returns the Subscribe and Resolve funcs of a subscription field. Subscribe
+// converts the typed event channel of its resolver into the channel expected by graphql-go.
+// Every event is then resolved as the value of the field.
+func (g *Generator) subscribeFuncs(b *builder, typ string, f *ast.Field) []goast.Expr {
+	resolve := b.field("Resolve", b.shortFunc(resolveFunc(b), b.ret(b.name("p.Source"), b.name("nil"))))
+	if !g.resolvers {
+		todo := b.field("Subscribe", b.shortFunc(resolveFunc(b), b.ret(b.name("nil"), b.name("nil"))))
+		return []goast.Expr{b.comment(todo, "TODO"), resolve}
+	}
+
+	// Unlike Resolve, there's no default, since graphql-go expects a channel of events
+	g.addImport("errors")
+	missing := b.call("errors.New", b.str("no subscription resolver for "+typ+"."+f.Name.Name))
+	body := []goast.Stmt{
+		b.ifStmt(nil, b.binary(b.name(typ+"Resolvers"), gotoken.EQL, b.name("nil")),
+			b.ret(b.name("nil"), missing),
+		),
+	}
+
+	args := []goast.Expr{b.name("p.Context")}
+	if f.Args.NumValues() > 0 {
+		body = append(body,
+			b.define("args, err", b.call("decode"+argsName(typ, f), b.name("p.Args"))),
+			b.ifErr(b.name("nil"), b.name("err")),
+		)
+		args = append(args, b.name("args"))
+	}
+
+	// The events are forwarded until the resolver closes them, or the context is done
+	done := func() goast.Stmt {
+		return b.commClause(b.exprStmt(b.recv(b.call("p.Context.Done"))), b.ret())
+	}
+	forward := b.selectStmt(
+		b.commClause(b.define("e, ok", b.recv(b.name("events"))),
+			b.ifStmt(nil, &goast.UnaryExpr{Op: gotoken.NOT, X: b.name("ok")}, b.ret()),
+			b.selectStmt(b.commClause(&goast.SendStmt{Chan: b.name("c"), Value: b.name("e")}), done()),
+		),
+		done(),
+	)
+	body = append(body,
+		b.define("events, err", b.call(typ+"Resolvers."+goName(f.Name.Name), args...)),
+		b.ifErr(b.name("nil"), b.name("err")),
+		b.define("c", b.call("make", &goast.ChanType{Dir: goast.SEND | goast.RECV, Value: anyType()})),
+		&goast.GoStmt{Call: &goast.CallExpr{Fun: b.funcLit(b.funcType(nil),
+			&goast.DeferStmt{Call: b.call("close", b.name("c"))},
+			b.forStmt(forward),
+		)}},
+		b.ret(b.name("c"), b.name("nil")),
+	)
+	return []goast.Expr{b.field("Subscribe", b.funcLit(resolveFunc(b), body...)), resolve}
+}
+
+// generateResolvers generates the resolver interface of every object type,
+// along with the typed arguments of their fields.
+func (g *Generator) generateResolvers(types []*ast.TypeDecl, descr bool) error {
+	b := newBuilder()
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok || isCompilerType(d, ts.TypeSpec) {
+			continue
+		}
+
+		obj, ok := ts.TypeSpec.Type.(*ast.TypeSpec_Object)
+		if !ok || g.isLibraryType(ts.TypeSpec.Name.Name) {
+			continue
+		}
+		name := ts.TypeSpec.Name.Name
+		g.section(g.typeFile(ts.TypeSpec))
+		g.addImport("context")
+
+		// Generate argument structs
+		var decls []goast.Decl
+		for _, f := range obj.Object.Fields.List {
+			if f.Args.NumValues() == 0 {
+				continue
+			}
+
+			fields := make([]*goast.Field, len(f.Args.List))
+			for i, a := range f.Args.List {
+				fields[i] = b.jsonField(descrDoc(b, descr, a.Doc), goName(a.Name.Name), b.typ(g.goType(inputType(a), false)), a.Name.Name)
+			}
+			doc := b.doc(argsName(name, f) + " contains the arguments of " + name + "." + f.Name.Name + ".")
+			decls = append(decls, b.typeDecl(doc, argsName(name, f), b.structType(fields...)))
+		}
+
+		// Generate resolver interface
+		methods := make([]*goast.Field, len(obj.Object.Fields.List))
+		for i, f := range obj.Object.Fields.List {
+			var params []*goast.Field
+			if !g.roots[name] {
+				params = append(params, b.param("obj", b.star(b.name(name))))
+			}
+			if f.Args.NumValues() > 0 {
+				params = append(params, b.param("args", b.name(argsName(name, f))))
+			}
+			methods[i] = g.resolverMethod(b, descrDoc(b, descr, f.Doc), name, f, params, g.goType(fieldType(f), false))
+		}
+
+		var doc, varDoc *goast.CommentGroup
+		if name == g.subscription {
+			doc = b.doc(name+"Resolver subscribes to the fields of "+name+". The event channels",
+				"must be closed by the resolver, at the latest when ctx is done.")
+			varDoc = b.doc(name + "Resolvers subscribes to the fields of " + name + ". If nil, subscriptions fail.")
+		} else {
+			doc = b.doc(name + "Resolver resolves the fields of " + name + ".")
+			varDoc = b.doc(name + "Resolvers resolves the fields of " + name + ". If nil, fields are resolved from their source value.")
+		}
+		decls = append(decls,
+			b.typeDecl(doc, name+"Resolver", b.interfaceType(methods...)),
+			b.withDoc(varDoc, b.zeroVar(name+"Resolvers", b.name(name+"Resolver"))),
+		)
+
+		if err := g.printDecls(b, decls...); err != nil {
+			return err
+		}
+	}
+
+	return g.generateDecoders(types)
+}
+
+// resolverMethod returns the method of a resolver interface, which resolves a field of the given
+// type into a value of res. Besides the context, it's given the given parameters. Subscriptions
+// return a channel of events instead.
+func (g *Generator) resolverMethod(b *builder, doc *goast.CommentGroup, typ string, f *ast.Field, params []*goast.Field, res string) *goast.Field {
+	if typ == g.subscription {
+		res = "<-chan " + res
+	}
+	params = append([]*goast.Field{b.param("ctx", b.name("context.Context"))}, params...)
+	return b.member(doc, goName(f.Name.Name), b.funcType(params, b.typ(res), b.name("error")))
+}
+
+// argsName returns the name of the Go struct containing a fields' arguments.
+func argsName(typ string, f *ast.Field) string {
+	return typ + goName(f.Name.Name) + "Args"
+}
@@ -0,0 +1,322 @@
+package golang
+
+import (
+	"fmt"
+	goast "go/ast"
+	gotoken "go/token"
+	"strings"
+)
+
+const graphqlASTImport = "github.com/graphql-go/graphql/language/ast"
+
+// goScalar is the Go type a custom scalar is mapped to.
+type goScalar struct {
+	// path is the import path of the types' package, if any
+	path string
+
+	// typ is the Go type expression, e.g. time.Time
+	typ string
+
+	// kind is the kind of a basic Go type, "json" for interface{} or "url" for url.URL.
+	// It's empty for any other type, which must then implement encoding.TextMarshaler
+	// and encoding.TextUnmarshaler.
+	kind string
+
+	// bits is the bit size of a basic numeric type, as understood by strconv
+	bits string
+}
+
+// basicKinds maps the basic Go types a scalar can be mapped to onto their kind.
+var basicKinds = map[string]string{
+	"string":  "string",
+	"bool":    "bool",
+	"int":     "int",
+	"int8":    "int",
+	"int16":   "int",
+	"int32":   "int",
+	"int64":   "int",
+	"uint":    "uint",
+	"uint8":   "uint",
+	"uint16":  "uint",
+	"uint32":  "uint",
+	"uint64":  "uint",
+	"float32": "float",
+	"float64": "float",
+}
+
+// wellKnownScalars are the scalars which can be mapped to Go types by name.
+var wellKnownScalars = map[string]goScalar{
+	"DateTime": {path: "time", typ: "time.Time"},
+	"JSON":     {typ: "interface{}", kind: "json"},
+	"UUID":     {path: "github.com/google/uuid", typ: "uuid.UUID"},
+	"Int64":    {typ: "int64", kind: "int", bits: "64"},
+	"URL":      {path: "net/url", typ: "url.URL", kind: "url"},
+}
+
+// parseGoScalar parses a Go type given as a, possibly package qualified, type name
+// e.g. int64, time.Time or github.com/google/uuid.UUID.
+func parseGoScalar(name, typ string) (s goScalar, err error) {
+	if kind, ok := basicKinds[typ]; ok {
+		bits := strings.TrimLeft(typ, "abcdefghijklmnopqrstuvwxyz")
+		if bits == "" {
+			bits = "0"
+		}
+		return goScalar{typ: typ, kind: kind, bits: bits}, nil
+	}
+	if typ == "interface{}" {
+		return wellKnownScalars["JSON"], nil
+	}
+
+	slash := strings.LastIndexByte(typ, '/')
+	dot := strings.LastIndexByte(typ, '.')
+	if dot < slash || dot == 0 || dot == len(typ)-1 {
+		return s, fmt.Errorf("invalid Go type for scalar %s: %s", name, typ)
+	}
+	if dot < 0 {
+		return goScalar{typ: typ}, nil
+	}
+
+	s.path = typ[:dot]
+	s.typ = packageName(s.path) + typ[dot:]
+	return
+}
+
+// scalarFuncs returns the Serialize, ParseValue and ParseLiteral funcs
+// of a scalar which is represented by the given Go type.
+func (g *Generator) scalarFuncs(b *builder, s goScalar) []goast.Expr {
+	if s.path != "" {
+		g.addImport(s.path)
+	}
+
+	// Values of any type are passed through as is
+	if s.kind == "json" {
+		funcs := []goast.Expr{
+			b.field("Serialize", b.shortFunc(valueFunc(b), b.ret(b.name("value")))),
+			b.field("ParseValue", b.shortFunc(valueFunc(b), b.ret(b.name("value")))),
+		}
+		if g.backend != backendExecutor {
+			g.jsonLiteral = true
+			funcs = append(funcs, b.field("ParseLiteral", b.name("parseJSONLiteral")))
+		}
+		return funcs
+	}
+	if g.backend != backendExecutor {
+		g.addImport(graphqlASTImport)
+	}
+
+	// Serialize accepts the Go type and a pointer to it
+	serialize := []goast.Stmt{
+		b.ifStmt(
+			b.define("v, ok", b.assert(b.name("value"), b.star(b.name(s.typ)))),
+			b.binary(b.name("ok"), gotoken.LAND, b.binary(b.name("v"), gotoken.NEQ, b.name("nil"))),
+			b.assign(b.name("value"), b.star(b.name("v"))),
+		),
+		b.define("v, ok", b.assert(b.name("value"), b.name(s.typ))),
+		unlessOK(b),
+	}
+	switch s.kind {
+	case "url":
+		serialize = append(serialize, b.ret(b.call("v.String")))
+	case "":
+		serialize = append(serialize,
+			b.define("b, err", b.call("v.MarshalText")),
+			b.ifErr(b.name("nil")),
+			b.ret(b.call("string", b.name("b"))),
+		)
+	default:
+		serialize = append(serialize, b.ret(b.name("v")))
+	}
+	funcs := []goast.Expr{b.field("Serialize", b.funcLit(valueFunc(b), serialize...))}
+
+	switch s.kind {
+	case "", "url":
+		return append(funcs, g.parseText(b, s)...)
+	case "string", "bool":
+		gqlValue := "StringValue"
+		if s.kind == "bool" {
+			gqlValue = "BooleanValue"
+		}
+
+		funcs = append(funcs, b.field("ParseValue", b.funcLit(valueFunc(b),
+			b.define("v, ok", b.assert(b.name("value"), b.name(s.kind))),
+			unlessOK(b),
+			b.ret(b.call(s.typ, b.name("v"))),
+		)))
+		if g.backend == backendExecutor {
+			return funcs
+		}
+
+		return append(funcs, b.field("ParseLiteral", b.funcLit(literalFunc(b),
+			b.define("v, ok", b.assert(b.name("valueAST"), b.star(b.name("ast."+gqlValue)))),
+			unlessOK(b),
+			b.ret(b.call(s.typ, b.name("v.Value"))),
+		)))
+	default:
+		return append(funcs, g.parseNumber(b, s)...)
+	}
+}
+
+// valueFunc returns the type of the funcs which serialize and parse values.
+func valueFunc(b *builder) *goast.FuncType {
+	return b.funcType([]*goast.Field{b.param("value", anyType())}, anyType())
+}
+
+// literalFunc returns the type of the funcs which parse literals.
+func literalFunc(b *builder) *goast.FuncType {
+	return b.funcType([]*goast.Field{b.param("valueAST", b.name("ast.Value"))}, anyType())
+}
+
+// unlessOK returns the check of ok, which returns nil unless it's true.
+func unlessOK(b *builder) goast.Stmt {
+	return b.ifStmt(nil, &goast.UnaryExpr{Op: gotoken.NOT, X: b.name("ok")}, b.ret(b.name("nil")))
+}
+
+// parseText returns the ParseValue and ParseLiteral funcs of a scalar
+// whose Go type implements encoding.TextUnmarshaler or is a url.URL.
+func (g *Generator) parseText(b *builder, s goScalar) []goast.Expr {
+	parseValue := []goast.Stmt{b.define("s, ok", b.assert(b.name("value"), b.name("string"))), unlessOK(b)}
+	funcs := []goast.Expr{
+		b.field("ParseValue", b.funcLit(valueFunc(b), append(parseValue, parseString(b, s, "s")...)...)),
+	}
+
+	// The executor backend parses literals into the same values as variables
+	if g.backend == backendExecutor {
+		return funcs
+	}
+
+	parseLiteral := []goast.Stmt{b.define("s, ok", b.assert(b.name("valueAST"), b.star(b.name("ast.StringValue")))), unlessOK(b)}
+	return append(funcs,
+		b.field("ParseLiteral", b.funcLit(literalFunc(b), append(parseLiteral, parseString(b, s, "s.Value")...)...)),
+	)
+}
+
+// parseString returns the parsing of a string into the scalars' Go type.
+func parseString(b *builder, s goScalar, str string) []goast.Stmt {
+	if s.kind == "url" {
+		return []goast.Stmt{
+			b.define("v, err", b.call("url.Parse", b.name(str))),
+			b.ifErr(b.name("nil")),
+			b.ret(b.star(b.name("v"))),
+		}
+	}
+
+	text := &goast.CallExpr{Fun: &goast.ArrayType{Elt: b.name("byte")}, Args: []goast.Expr{b.name(str)}}
+	return []goast.Stmt{
+		b.varStmt("v", b.name(s.typ)),
+		b.ifStmt(
+			b.define("err", b.call("v.UnmarshalText", text)),
+			b.binary(b.name("err"), gotoken.NEQ, b.name("nil")),
+			b.ret(b.name("nil")),
+		),
+		b.ret(b.name("v")),
+	}
+}
+
+// parseNumber returns the ParseValue and ParseLiteral funcs of a scalar
+// whose Go type is numeric. Integers may also be given as strings, since
+// they can exceed the precision of JSON numbers.
+func (g *Generator) parseNumber(b *builder, s goScalar) []goast.Expr {
+	g.addImport("strconv")
+
+	var parse func(str string) goast.Expr
+	var literals []string
+	switch s.kind {
+	case "int":
+		parse = func(str string) goast.Expr {
+			return b.call("strconv.ParseInt", b.name(str), b.lit(gotoken.INT, "10"), b.lit(gotoken.INT, s.bits))
+		}
+		literals = []string{"IntValue", "StringValue"}
+	case "uint":
+		parse = func(str string) goast.Expr {
+			return b.call("strconv.ParseUint", b.name(str), b.lit(gotoken.INT, "10"), b.lit(gotoken.INT, s.bits))
+		}
+		literals = []string{"IntValue", "StringValue"}
+	case "float":
+		parse = func(str string) goast.Expr {
+			return b.call("strconv.ParseFloat", b.name(str), b.lit(gotoken.INT, s.bits))
+		}
+		literals = []string{"IntValue", "FloatValue"}
+	}
+
+	values := []goast.Stmt{
+		b.caseClause([]goast.Expr{b.name("int")}, b.ret(b.call(s.typ, b.name("v")))),
+		b.caseClause([]goast.Expr{b.name("float64")}, b.ret(b.call(s.typ, b.name("v")))),
+	}
+	if s.kind != "float" {
+		values = append(values, b.caseClause([]goast.Expr{b.name("string")}, parseReturn(b, s, parse("v"))...))
+	}
+	funcs := []goast.Expr{b.field("ParseValue", b.funcLit(valueFunc(b),
+		b.typeSwitch("v", b.name("value"), values...),
+		b.ret(b.name("nil")),
+	))}
+	if g.backend == backendExecutor {
+		return funcs
+	}
+
+	var cases []goast.Stmt
+	for _, lit := range literals {
+		cases = append(cases, b.caseClause([]goast.Expr{b.star(b.name("ast." + lit))}, b.assign(b.name("s"), b.name("v.Value"))))
+	}
+	cases = append(cases, b.caseClause(nil, b.ret(b.name("nil"))))
+
+	parseLiteral := []goast.Stmt{b.varStmt("s", b.name("string")), b.typeSwitch("v", b.name("valueAST"), cases...)}
+	return append(funcs,
+		b.field("ParseLiteral", b.funcLit(literalFunc(b), append(parseLiteral, parseReturn(b, s, parse("s"))...)...)),
+	)
+}
+
+// parseReturn returns the return of a parsed number, as the scalars' Go type.
+func parseReturn(b *builder, s goScalar, parse goast.Expr) []goast.Stmt {
+	return []goast.Stmt{
+		b.define("n, err", parse),
+		b.ifErr(b.name("nil")),
+		b.ret(b.call(s.typ, b.name("n"))),
+	}
+}
+
+// printJSONLiteral prints the func which parses literals of
+// JSON scalars into their Go values, as encoding/json would.
+func (g *Generator) printJSONLiteral() error {
+	g.addImport("strconv")
+	g.addImport(graphqlASTImport)
+
+	b := newBuilder()
+	value := func(typ string) goast.Stmt {
+		return b.caseClause([]goast.Expr{b.star(b.name("ast." + typ))}, b.ret(b.name("v.Value")))
+	}
+	float := func(typ string) goast.Stmt {
+		return b.caseClause([]goast.Expr{b.star(b.name("ast." + typ))},
+			b.define("f, _", b.call("strconv.ParseFloat", b.name("v.Value"), b.lit(gotoken.INT, "64"))),
+			b.ret(b.name("f")),
+		)
+	}
+
+	list := b.caseClause([]goast.Expr{b.star(b.name("ast.ListValue"))},
+		b.define("l", b.call("make", anyList(), b.call("len", b.name("v.Values")))),
+		b.rangeStmt("i", "e", b.name("v.Values"),
+			b.assign(b.index(b.name("l"), b.name("i")), b.call("parseJSONLiteral", b.name("e"))),
+		),
+		b.ret(b.name("l")),
+	)
+	object := b.caseClause([]goast.Expr{b.star(b.name("ast.ObjectValue"))},
+		b.define("m", b.call("make", anyMap(), b.call("len", b.name("v.Fields")))),
+		b.rangeStmt("_", "f", b.name("v.Fields"),
+			b.assign(b.index(b.name("m"), b.name("f.Name.Value")), b.call("parseJSONLiteral", b.name("f.Value"))),
+		),
+		b.ret(b.name("m")),
+	)
+
+	return g.printDecl(b, b.docFunc("parseJSONLiteral parses a literal of a JSON scalar into its Go value.",
+		"parseJSONLiteral", literalFunc(b),
+		b.typeSwitch("v", b.name("valueAST"),
+			value("StringValue"),
+			value("EnumValue"),
+			value("BooleanValue"),
+			float("IntValue"),
+			float("FloatValue"),
+			list,
+			object,
+		),
+		b.ret(b.name("nil")),
+	))
+}
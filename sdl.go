@@ -0,0 +1,316 @@
+package golang
+
+import (
+	"github.com/gqlc/graphql/ast"
+	gotoken "go/token"
+	"strings"
+)
+
+// sdlPrinter prints types in the GraphQL schema definition language.
+type sdlPrinter struct {
+	strings.Builder
+
+	// descr reports whether descriptions are printed
+	descr bool
+}
+
+// printSDL returns the schema and types in the GraphQL schema definition language. The output
+// is canonical i.e. it doesn't depend on the formatting of the source document. Descriptions
+// are only included if descr is set.
+//
+// Backends which parse the schema at runtime embed it as their Schema constant. Since the
+// generator is only given the AST of a document, with its extensions merged, it's also the
+// form the schema hash is computed from.
+func printSDL(schema *ast.TypeDecl, types []*ast.TypeDecl, descr bool) string {
+	b := &sdlPrinter{descr: descr}
+	if schema != nil {
+		b.typeDecl(schema)
+	}
+
+	for _, d := range types {
+		ts, ok := d.Spec.(*ast.TypeDecl_TypeSpec)
+		if !ok || isCompilerType(d, ts.TypeSpec) {
+			continue
+		}
+		if _, ok = ts.TypeSpec.Type.(*ast.TypeSpec_Schema); ok {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.typeDecl(d)
+	}
+	return b.String()
+}
+
+// declSDL returns a type declaration in the schema definition language, along with its description.
+func declSDL(d *ast.TypeDecl) string {
+	b := &sdlPrinter{descr: true}
+	b.typeDecl(d)
+	return b.String()
+}
+
+// printSchemaConst prints the schema, in the schema definition language, as the
+// Schema constant for backends which parse the schema themselves.
+func (g *Generator) printSchemaConst(schema *ast.TypeDecl, types []*ast.TypeDecl, descr bool) error {
+	b := newBuilder()
+	sdl := goString(strings.TrimSuffix(printSDL(schema, types, descr), "\n"))
+	doc := b.doc("Schema is the GraphQL schema in the schema definition language.")
+	return g.printDecl(b, b.constDecl(doc, "Schema", b.lit(gotoken.STRING, sdl)))
+}
+
+// typeDecl prints a type declaration, along with its description.
+func (b *sdlPrinter) typeDecl(d *ast.TypeDecl) {
+	ts := d.Spec.(*ast.TypeDecl_TypeSpec).TypeSpec
+	b.description("", d.Doc)
+
+	switch v := ts.Type.(type) {
+	case *ast.TypeSpec_Schema:
+		b.WriteString("schema")
+		b.directives(ts.Directives)
+		b.fields(v.Schema.RootOps)
+	case *ast.TypeSpec_Scalar:
+		b.WriteString("scalar " + ts.Name.Name)
+		b.directives(ts.Directives)
+		b.WriteByte('\n')
+	case *ast.TypeSpec_Object:
+		b.WriteString("type " + ts.Name.Name)
+		for i, inter := range v.Object.Interfaces {
+			if i == 0 {
+				b.WriteString(" implements ")
+			} else {
+				b.WriteString(" & ")
+			}
+			b.WriteString(inter.Name)
+		}
+		b.directives(ts.Directives)
+		b.fields(v.Object.Fields)
+	case *ast.TypeSpec_Interface:
+		b.WriteString("interface " + ts.Name.Name)
+		b.directives(ts.Directives)
+		b.fields(v.Interface.Fields)
+	case *ast.TypeSpec_Union:
+		b.WriteString("union " + ts.Name.Name)
+		b.directives(ts.Directives)
+		for i, mem := range v.Union.Members {
+			if i == 0 {
+				b.WriteString(" = ")
+			} else {
+				b.WriteString(" | ")
+			}
+			b.WriteString(mem.Name)
+		}
+		b.WriteByte('\n')
+	case *ast.TypeSpec_Enum:
+		b.WriteString("enum " + ts.Name.Name)
+		b.directives(ts.Directives)
+		b.fields(v.Enum.Values)
+	case *ast.TypeSpec_Input:
+		b.WriteString("input " + ts.Name.Name)
+		b.directives(ts.Directives)
+		if v.Input.Fields.NumValues() == 0 {
+			b.WriteByte('\n')
+			break
+		}
+
+		b.WriteString(" {\n")
+		for _, f := range v.Input.Fields.List {
+			b.inputValue("\t", f)
+			b.WriteByte('\n')
+		}
+		b.WriteString("}\n")
+	case *ast.TypeSpec_Directive:
+		b.WriteString("directive @" + ts.Name.Name)
+		b.args("", v.Directive.Args)
+		for i, loc := range v.Directive.Locs {
+			if i == 0 {
+				b.WriteString(" on ")
+			} else {
+				b.WriteString(" | ")
+			}
+			b.WriteString(loc.Loc.String())
+		}
+		b.WriteByte('\n')
+	}
+}
+
+// fields prints a list of fields, enum values or root operations enclosed by braces.
+func (b *sdlPrinter) fields(fields *ast.FieldList) {
+	if fields == nil || len(fields.List) == 0 {
+		b.WriteByte('\n')
+		return
+	}
+
+	b.WriteString(" {\n")
+	for _, f := range fields.List {
+		b.description("\t", f.Doc)
+		b.WriteString("\t" + f.Name.Name)
+		b.args("\t", f.Args)
+		if typ := fieldType(f); typ != nil {
+			b.WriteString(": ")
+			b.typeRef(typ)
+		}
+		b.directives(f.Directives)
+		b.WriteByte('\n')
+	}
+	b.WriteString("}\n")
+}
+
+// args prints the argument definitions of a field or directive, given its indentation.
+// They're printed on separate lines, if any of them has a description.
+func (b *sdlPrinter) args(indent string, args *ast.InputValueList) {
+	if args.NumValues() == 0 {
+		return
+	}
+
+	multiline := false
+	for _, a := range args.List {
+		multiline = multiline || (b.descr && descrText(a.Doc) != "")
+	}
+
+	b.WriteByte('(')
+	for i, a := range args.List {
+		switch {
+		case multiline:
+			b.WriteByte('\n')
+			b.inputValue(indent+"\t", a)
+		case i > 0:
+			b.WriteString(", ")
+			b.inputValue("", a)
+		default:
+			b.inputValue("", a)
+		}
+	}
+	if multiline {
+		b.WriteString("\n" + indent)
+	}
+	b.WriteByte(')')
+}
+
+// inputValue prints an argument or input field definition.
+func (b *sdlPrinter) inputValue(indent string, a *ast.InputValue) {
+	b.description(indent, a.Doc)
+	b.WriteString(indent + a.Name.Name + ": ")
+	b.typeRef(inputType(a))
+
+	switch v := a.Default.(type) {
+	case *ast.InputValue_BasicLit:
+		b.WriteString(" = ")
+		b.value(v.BasicLit)
+	case *ast.InputValue_CompositeLit:
+		b.WriteString(" = ")
+		b.value(v.CompositeLit)
+	}
+	b.directives(a.Directives)
+}
+
+// typeRef prints a type reference.
+func (b *sdlPrinter) typeRef(typ interface{}) {
+	switch v := typ.(type) {
+	case *ast.Ident:
+		b.WriteString(v.Name)
+	case *ast.List:
+		b.WriteByte('[')
+		switch w := v.Type.(type) {
+		case *ast.List_Ident:
+			b.typeRef(w.Ident)
+		case *ast.List_List:
+			b.typeRef(w.List)
+		case *ast.List_NonNull:
+			b.typeRef(w.NonNull)
+		}
+		b.WriteByte(']')
+	case *ast.NonNull:
+		switch w := v.Type.(type) {
+		case *ast.NonNull_Ident:
+			b.typeRef(w.Ident)
+		case *ast.NonNull_List:
+			b.typeRef(w.List)
+		}
+		b.WriteByte('!')
+	}
+}
+
+// value prints a value literal, as it was written.
+func (b *sdlPrinter) value(val interface{}) {
+	switch v := val.(type) {
+	case *ast.BasicLit:
+		b.WriteString(v.Value)
+	case *ast.CompositeLit:
+		switch w := v.Value.(type) {
+		case *ast.CompositeLit_BasicLit:
+			b.value(w.BasicLit)
+		case *ast.CompositeLit_ListLit:
+			b.value(w.ListLit)
+		case *ast.CompositeLit_ObjLit:
+			b.value(w.ObjLit)
+		}
+	case *ast.ListLit:
+		b.WriteByte('[')
+		for i, e := range listValues(v) {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.value(e)
+		}
+		b.WriteByte(']')
+	case *ast.ObjLit:
+		b.WriteByte('{')
+		for i, p := range v.Fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(p.Key.Name + ": ")
+			b.value(p.Val)
+		}
+		b.WriteByte('}')
+	}
+}
+
+// directives prints applied directives.
+func (b *sdlPrinter) directives(directives []*ast.DirectiveLit) {
+	for _, d := range directives {
+		b.WriteString(" @" + d.Name)
+		if d.Args == nil || len(d.Args.Args) == 0 {
+			continue
+		}
+
+		b.WriteByte('(')
+		for i, arg := range d.Args.Args {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(arg.Name.Name + ": ")
+			switch v := arg.Value.(type) {
+			case *ast.Arg_BasicLit:
+				b.value(v.BasicLit)
+			case *ast.Arg_CompositeLit:
+				b.value(v.CompositeLit)
+			}
+		}
+		b.WriteByte(')')
+	}
+}
+
+// description prints a description, as a block string if it spans multiple lines.
+func (b *sdlPrinter) description(indent string, doc *ast.DocGroup) {
+	text := descrText(doc)
+	if text == "" || !b.descr {
+		return
+	}
+
+	if !strings.ContainsAny(text, "\n\"\\") {
+		b.WriteString(indent + `"` + text + `"` + "\n")
+		return
+	}
+
+	b.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(strings.Replace(text, `"""`, `\"""`, -1), "\n") {
+		if line != "" {
+			b.WriteString(indent + line)
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
@@ -0,0 +1,121 @@
+package golang
+
+import (
+	"github.com/gqlc/graphql/ast"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// descrText returns the text of a description, without a trailing newline.
+// Unlike ast.DocGroup.Text, strings are interpreted as per the GraphQL spec i.e.
+// escape sequences are resolved and block strings keep their empty lines.
+func descrText(doc *ast.DocGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	lines := make([]string, 0, len(doc.List))
+	for _, d := range doc.List {
+		if d.Comment {
+			line := strings.TrimPrefix(d.Text, "#")
+			lines = append(lines, strings.TrimRight(strings.TrimPrefix(line, " "), " \t"))
+			continue
+		}
+		lines = append(lines, stringValue(d.Text))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// stringValue returns the value of a GraphQL string or block string literal.
+func stringValue(lit string) string {
+	if len(lit) >= 6 && strings.HasPrefix(lit, `"""`) && strings.HasSuffix(lit, `"""`) {
+		return blockStringValue(lit[3 : len(lit)-3])
+	}
+	if len(lit) < 2 || lit[0] != '"' || lit[len(lit)-1] != '"' {
+		return lit
+	}
+	lit = lit[1 : len(lit)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(lit); i++ {
+		if lit[i] != '\\' || i+1 == len(lit) {
+			b.WriteByte(lit[i])
+			continue
+		}
+
+		i++
+		switch lit[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if i+5 <= len(lit) {
+				r, err := strconv.ParseUint(lit[i+1:i+5], 16, 32)
+				if err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					break
+				}
+			}
+			b.WriteString(`\u`)
+		default:
+			b.WriteByte(lit[i])
+		}
+	}
+	return b.String()
+}
+
+// blockStringValue returns the value of a block string, given its raw contents.
+// The common indentation and any leading and trailing blank lines are removed.
+func blockStringValue(raw string) string {
+	raw = strings.Replace(raw, `\"""`, `"""`, -1)
+	raw = strings.Replace(raw, "\r\n", "\n", -1)
+	lines := strings.Split(strings.Replace(raw, "\r", "\n", -1), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if n < len(line) && (indent < 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i, line := range lines[1:] {
+			if len(line) < indent {
+				lines[i+1] = ""
+				continue
+			}
+			lines[i+1] = line[indent:]
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// goString returns a Go string literal of s. Multi-line text is written as a raw
+// string, if possible, to keep it readable; anything else is quoted.
+func goString(s string) string {
+	if !strings.Contains(s, "\n") || strings.HasSuffix(s, "\n") || strings.ContainsAny(s, "`\r") || !utf8.ValidString(s) {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !strconv.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return "`" + s + "`"
+}
@@ -55,11 +55,131 @@ var types = []*ast.TypeDecl{
 								Value: "false",
 							}},
 						},
+						{
+							Name: &ast.Ident{Name: "models"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "Boolean"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_BOOL,
+								Value: "false",
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "resolvers"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "Boolean"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_BOOL,
+								Value: "false",
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "reachableTypes"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "Boolean"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_BOOL,
+								Value: "false",
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "layout"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "String"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_STRING,
+								Value: `"single"`,
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "version"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "Boolean"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_BOOL,
+								Value: "false",
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "schemaHash"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "Boolean"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_BOOL,
+								Value: "false",
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "backend"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "String"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_STRING,
+								Value: `"graphql-go"`,
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "format"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "Boolean"},
+							},
+							Default: &ast.InputValue_BasicLit{BasicLit: &ast.BasicLit{
+								Kind:  token.Token_BOOL,
+								Value: "true",
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "scalars"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "GoTypeMap"},
+							},
+						},
+						{
+							Name: &ast.Ident{Name: "wellKnownScalars"},
+							Type: &ast.InputValue_List{List: &ast.List{
+								Type: &ast.List_NonNull{NonNull: &ast.NonNull{
+									Type: &ast.NonNull_Ident{
+										Ident: &ast.Ident{Name: "String"},
+									},
+								}},
+							}},
+						},
+						{
+							Name: &ast.Ident{Name: "libraries"},
+							Type: &ast.InputValue_Ident{
+								Ident: &ast.Ident{Name: "PackageMap"},
+							},
+						},
 					},
 				},
 			}},
 		}},
 	},
+	{
+		Tok: token.Token_SCALAR,
+		Spec: &ast.TypeDecl_TypeSpec{TypeSpec: &ast.TypeSpec{
+			Name: &ast.Ident{Name: "GoTypeMap"},
+			Type: &ast.TypeSpec_Scalar{
+				Scalar: &ast.ScalarType{Name: &ast.Ident{Name: "GoTypeMap"}},
+			},
+		}},
+	},
+	{
+		Tok: token.Token_SCALAR,
+		Spec: &ast.TypeDecl_TypeSpec{TypeSpec: &ast.TypeSpec{
+			Name: &ast.Ident{Name: "PackageMap"},
+			Type: &ast.TypeSpec_Scalar{
+				Scalar: &ast.ScalarType{Name: &ast.Ident{Name: "PackageMap"}},
+			},
+		}},
+	},
 }
 
 func init() {